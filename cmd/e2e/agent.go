@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/lueurxax/e2e/pkg/agent"
+	"github.com/lueurxax/e2e/pkg/config"
+	"github.com/lueurxax/e2e/pkg/log"
+	"github.com/lueurxax/e2e/pkg/models"
+	"github.com/lueurxax/e2e/pkg/testerspool"
+)
+
+const (
+	// agentTokenEnv environment variable with shared token of coordinator and agents
+	agentTokenEnv = "E2E_AGENT_TOKEN"
+	// shutdownTimeout time of running jobs to finish after signal
+	shutdownTimeout = 10 * time.Second
+)
+
+// runAgent run agent of distributed stress load with declarative, script and plugin testers of config.
+// Testers of generated state aren't available, their agent is built with generated state by project.
+func runAgent(args []string) error {
+	flags := flag.NewFlagSet("agent", flag.ExitOnError)
+	path := flags.String("config", "config.yaml", "config file or directory")
+	env := flags.String("env", "", "environment overlay from envs/<env>.yaml")
+	addr := flags.String("addr", ":8081", "listen address of agent")
+	token := flags.String("token", os.Getenv(agentTokenEnv), "shared token of coordinator, "+agentTokenEnv+" by default")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	conf := config.NewConfig(*path, config.WithEnv(*env))
+	if err := conf.Read(); err != nil {
+		return err
+	}
+	defer conf.Close()
+	clients, err := conf.Clients()
+	if err != nil {
+		return err
+	}
+
+	logger := log.NewLogger(logrus.New())
+	state := agent.NewRawState()
+	pool, err := testerspool.NewTestersPoolWithOptions(nil,
+		testerspool.WithHTTP(conf.HTTPTesters(), state, clients),
+		testerspool.WithGRPC(conf.GRPCTesters(), state, clients),
+		testerspool.WithScripts(conf.ScriptTesters(), state, clients, logger),
+		testerspool.WithPlugins(conf.PluginsDir(), state, clients, logger),
+	)
+	if err != nil {
+		return err
+	}
+	if closer, ok := pool.(io.Closer); ok {
+		defer closer.Close()
+	}
+	// raw state doesn't keep states of jobs, so all jobs share it
+	handler, err := agent.NewServer(pool, func() models.State { return state }, *token, logger)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	srv := &http.Server{Addr: *addr, Handler: handler}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+	logger.WithField("addr", *addr).Info("agent started")
+	if err = srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+// Command e2e tools of e2e framework for work with configs and agent of distributed stress load
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// command of cli, it parses its own flags
+type command struct {
+	usage string
+	run   func(args []string) error
+}
+
+var commands = map[string]command{
+	"schema":  {usage: "export JSON Schema of config for editors", run: schema},
+	"plan":    {usage: "print resolved execution plan of scenarios without requests", run: plan},
+	"testers": {usage: "list declarative testers of config with their fields", run: testers},
+	"agent":   {usage: "run agent of distributed stress load with testers of config", run: runAgent},
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		usage()
+		os.Exit(2)
+	}
+	if err := cmd.run(os.Args[2:]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintln(os.Stderr, "usage: e2e <command> [flags]\n\ncommands:")
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", name, commands[name].usage)
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/config"
+	"github.com/lueurxax/e2e/pkg/models"
+	"github.com/lueurxax/e2e/pkg/processor"
+	"github.com/lueurxax/e2e/pkg/testerspool"
+)
+
+// plan print resolved execution plans of scenarios of config without requests.
+// Cli knows only declarative testers of config, plan query of running server knows registered testers too.
+func plan(args []string) error {
+	flags := flag.NewFlagSet("plan", flag.ExitOnError)
+	path := flags.String("config", "config.yaml", "config file or directory")
+	env := flags.String("env", "", "environment overlay from envs/<env>.yaml")
+	asJSON := flags.Bool("json", false, "print plans as json")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "usage: e2e plan [flags] [scenario...]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	conf := config.NewConfig(*path, config.WithEnv(*env))
+	defer func() {
+		_ = conf.Close()
+	}()
+	if err := conf.Read(); err != nil {
+		return err
+	}
+	if err := conf.Validate(); err != nil {
+		return err
+	}
+	conf.Init()
+	scenarios, err := conf.GetScenarios()
+	if err != nil {
+		return err
+	}
+	if scenarios, err = selectScenarios(scenarios, flags.Args()); err != nil {
+		return err
+	}
+	pool := testerspool.NewTestersPool(declaredTesters(conf))
+	plans := make([]*models.ScenarioPlan, len(scenarios))
+	for i, scenario := range scenarios {
+		plans[i] = processor.NewPlan(scenario, pool)
+	}
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(plans)
+	}
+	for _, scenarioPlan := range plans {
+		printPlan(os.Stdout, scenarioPlan)
+	}
+	return nil
+}
+
+func selectScenarios(scenarios []models.Scenario, names []string) ([]models.Scenario, error) {
+	if len(names) == 0 {
+		return scenarios, nil
+	}
+	index := make(map[string]int, len(scenarios))
+	for i, scenario := range scenarios {
+		index[scenario.Name] = i
+	}
+	selected := make([]models.Scenario, len(names))
+	for i, name := range names {
+		scenarioIndex, ok := index[name]
+		if !ok {
+			return nil, common.ErrUnknownScenario(name)
+		}
+		selected[i] = scenarios[scenarioIndex]
+	}
+	return selected, nil
+}
+
+func printPlan(w io.Writer, plan *models.ScenarioPlan) {
+	load := "repeat"
+	switch {
+	case plan.Streaming:
+		load = "streaming stress load"
+	case plan.StressLoad:
+		load = "stress load"
+	}
+	fmt.Fprintf(w, "scenario %s: %d shoots, %s\n", plan.Scenario, plan.ShootCount, load)
+	if len(plan.Params) > 0 {
+		fmt.Fprintln(w, "  params:")
+		for _, param := range plan.Params {
+			fmt.Fprintf(w, "    %s = %s\n", param.Name, param.Value)
+		}
+	}
+	for i, stage := range plan.Stages {
+		fmt.Fprintf(w, "  %d. %s: tester %s", i+1, stage.Stage, stage.Tester)
+		if stage.Client != "" {
+			fmt.Fprintf(w, ", client %s", stage.Client)
+		}
+		fmt.Fprintf(w, ", %d requests", stage.RequestsCount)
+		if stage.WantError {
+			fmt.Fprintf(w, ", want error %q", stage.Error)
+		}
+		fmt.Fprintln(w)
+		printFields(w, "params", stage.Params)
+		printFields(w, "required", stage.Required)
+		printFields(w, "returned", stage.Returned)
+		printFields(w, "missing", stage.Missing)
+	}
+	if len(plan.Issues) > 0 {
+		fmt.Fprintln(w, "  issues:")
+		for _, issue := range plan.Issues {
+			fmt.Fprintf(w, "    %s\n", issue)
+		}
+	}
+}
+
+func printFields(w io.Writer, name string, fields []string) {
+	if len(fields) > 0 {
+		fmt.Fprintf(w, "       %s: %s\n", name, strings.Join(fields, ", "))
+	}
+}
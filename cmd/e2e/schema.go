@@ -0,0 +1,27 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/lueurxax/e2e/pkg/config"
+)
+
+// schema write JSON Schema of config to file or stdout
+func schema(args []string) error {
+	flags := flag.NewFlagSet("schema", flag.ExitOnError)
+	output := flags.String("o", "", "output file, stdout by default")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	data, err := config.JSONSchema()
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if *output == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*output, data, 0o644)
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/config"
+	"github.com/lueurxax/e2e/pkg/models"
+	"github.com/lueurxax/e2e/pkg/testerspool"
+)
+
+// testers print declarative testers of config with their fields.
+// Cli knows only declarative testers of config, testers query of running server knows registered testers too.
+func testers(args []string) error {
+	flags := flag.NewFlagSet("testers", flag.ExitOnError)
+	path := flags.String("config", "config.yaml", "config file or directory")
+	env := flags.String("env", "", "environment overlay from envs/<env>.yaml")
+	asJSON := flags.Bool("json", false, "print testers as json")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	conf := config.NewConfig(*path, config.WithEnv(*env))
+	if err := conf.Read(); err != nil {
+		return err
+	}
+	metas := testerspool.Describe(testerspool.NewTestersPool(declaredTesters(conf)), nil)
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(metas)
+	}
+	for _, meta := range metas {
+		fmt.Printf("%s: %s", meta.Name, meta.Description)
+		if len(meta.Clients) > 0 {
+			fmt.Printf(" (clients: %s)", strings.Join(meta.Clients, ", "))
+		}
+		fmt.Println()
+		printMetaFields("required", meta.Required)
+		printMetaFields("returned", meta.Returned)
+	}
+	return nil
+}
+
+func printMetaFields(name string, fields []models.FieldMeta) {
+	if len(fields) == 0 {
+		return
+	}
+	names := make([]string, len(fields))
+	for i, field := range fields {
+		names[i] = field.Name
+		if field.Type != "" {
+			names[i] += " " + field.Type
+		}
+	}
+	fmt.Printf("    %s: %s\n", name, strings.Join(names, ", "))
+}
+
+// declaredTester fields of declarative tester of config, it is never run, script testers support any client
+type declaredTester struct {
+	name        string
+	description string
+	kind        string
+	required    []string
+	returned    []string
+}
+
+func (t *declaredTester) MethodName() string {
+	return t.name
+}
+
+func (t *declaredTester) RequiredFields() []string {
+	return t.required
+}
+
+func (t *declaredTester) ReturnedFields() []string {
+	return t.returned
+}
+
+func (t *declaredTester) Description() string {
+	return t.description
+}
+
+func (t *declaredTester) ClientKinds() []string {
+	if t.kind == "" {
+		return nil
+	}
+	return []string{t.kind}
+}
+
+func (t *declaredTester) Run(
+	context.Context, string, models.StateSelector, *models.Options,
+) (models.StateSelector, error) {
+	return nil, errors.New("cli doesn't run testers")
+}
+
+func declaredTesters(conf config.Configurator) []models.Tester {
+	testers := make([]models.Tester, 0)
+	for _, tester := range conf.HTTPTesters() {
+		testers = append(testers, &declaredTester{
+			name:        tester.Name,
+			description: tester.GetDescription(),
+			kind:        common.ClientHTTP,
+			required:    tester.Required,
+			returned:    extracted(tester.Extract),
+		})
+	}
+	for _, tester := range conf.GRPCTesters() {
+		testers = append(testers, &declaredTester{
+			name:        tester.Name,
+			description: tester.GetDescription(),
+			kind:        common.ClientGRPC,
+			required:    tester.Required,
+			returned:    extracted(tester.Extract),
+		})
+	}
+	for _, tester := range conf.ScriptTesters() {
+		testers = append(testers, &declaredTester{
+			name:        tester.Name,
+			description: tester.GetDescription(),
+			required:    tester.Required,
+			returned:    tester.Returned,
+		})
+	}
+	return testers
+}
+
+func extracted(extract map[string]string) []string {
+	fields := make([]string, 0, len(extract))
+	for field := range extract {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/lueurxax/e2e/pkg/config"
+	"github.com/lueurxax/e2e/pkg/models"
+	"github.com/lueurxax/e2e/pkg/testerspool"
+)
+
+const testersConfig = `
+http_testers:
+  - name: GetUser
+    url: /users/{{.user_id}}
+    required: [user_id]
+    extract: {name: $.name, email: $.email}
+grpc_testers:
+  - name: GetItem
+    method: items.Items/Get
+    reflection: true
+    description: item by id
+script_testers:
+  - name: Calc
+    file: calc.star
+    required: [a]
+    returned: [b]
+`
+
+func TestDeclaredTesters(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte(testersConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	conf := config.NewConfig(path)
+	if err := conf.Read(); err != nil {
+		t.Fatal(err)
+	}
+	fields := func(names ...string) []models.FieldMeta {
+		result := make([]models.FieldMeta, len(names))
+		for i, name := range names {
+			result[i] = models.FieldMeta{Name: name}
+		}
+		return result
+	}
+	expected := []models.TesterMeta{
+		{
+			Name:        "Calc",
+			Description: "script " + filepath.Join(dir, "calc.star"),
+			Required:    fields("a"),
+			Returned:    fields("b"),
+			Clients:     []string{},
+		},
+		{
+			Name:        "GetItem",
+			Description: "item by id",
+			Required:    fields(),
+			Returned:    fields(),
+			Clients:     []string{"grpc"},
+		},
+		{
+			Name:        "GetUser",
+			Description: "GET /users/{{.user_id}}",
+			Required:    fields("user_id"),
+			Returned:    fields("email", "name"),
+			Clients:     []string{"http"},
+		},
+	}
+	metas := testerspool.Describe(testerspool.NewTestersPool(declaredTesters(conf)), nil)
+	if !reflect.DeepEqual(metas, expected) {
+		t.Fatalf("expected testers %+v, got %+v", expected, metas)
+	}
+}
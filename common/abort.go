@@ -0,0 +1,77 @@
+package common
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultAbortWindow = 10 * time.Second
+	// DefaultAbortMinRequests default count of requests in sliding window required before error rate
+	// and latency checks, so the first failed request doesn't trip error rate
+	DefaultAbortMinRequests = 100
+)
+
+// AbortCriteria conditions for early stop of stress load. Sliding window is measured by timestamps of samples,
+// samples coming out of order are placed by timestamp, samples older than window are ignored.
+type AbortCriteria struct {
+	// ErrorRate limit of failed requests in sliding window, number (0-1) or percent, e.g. "50%"
+	ErrorRate string `yaml:"error_rate"`
+	// Window size of sliding window, 10s by default
+	Window time.Duration `yaml:"window"`
+	// MinRequests in sliding window required before error rate and latency checks, DefaultAbortMinRequests by default
+	MinRequests int `yaml:"min_requests"`
+	// ConsecutiveFailures limit of failed requests in a row
+	ConsecutiveFailures int `yaml:"consecutive_failures"`
+	// LatencyCeiling limit of average latency in sliding window
+	LatencyCeiling time.Duration `yaml:"latency_ceiling"`
+}
+
+// GetWindow return sliding window size
+func (a *AbortCriteria) GetWindow() time.Duration {
+	if a.Window <= 0 {
+		return defaultAbortWindow
+	}
+	return a.Window
+}
+
+// GetMinRequests return count of requests in sliding window required before error rate and latency checks
+func (a *AbortCriteria) GetMinRequests() int {
+	if a.MinRequests <= 0 {
+		return DefaultAbortMinRequests
+	}
+	return a.MinRequests
+}
+
+// GetErrorRate return parsed error rate limit, zero means without limit
+func (a *AbortCriteria) GetErrorRate() (rate float64, err error) {
+	if a.ErrorRate == "" {
+		return 0, nil
+	}
+	return parseRate(a.ErrorRate)
+}
+
+// Validate abort criteria
+func (a *AbortCriteria) Validate() error {
+	rate, err := a.GetErrorRate()
+	if err != nil || rate < 0 || rate > 1 {
+		return ErrInvalidConfig("abort error_rate must be a number between 0 and 1 or percent")
+	}
+	if a.Window < 0 || a.LatencyCeiling < 0 || a.MinRequests < 0 || a.ConsecutiveFailures < 0 {
+		return ErrInvalidConfig("abort criteria cannot be negative")
+	}
+	return nil
+}
+
+// parseRate parse part (0-1) from number or percent
+func parseRate(value string) (rate float64, err error) {
+	percent := strings.HasSuffix(value, "%")
+	if rate, err = strconv.ParseFloat(strings.TrimSuffix(value, "%"), 64); err != nil {
+		return
+	}
+	if percent {
+		rate /= 100
+	}
+	return
+}
@@ -0,0 +1,42 @@
+package common
+
+import (
+	"context"
+	"sync"
+)
+
+// Broadcaster send reports of stress load (live progress, checkpoints) to subscribers
+type Broadcaster[T any] struct {
+	mu        sync.RWMutex
+	listeners map[chan<- *T]struct{}
+}
+
+// Subscribe listener until context is done
+func (b *Broadcaster[T]) Subscribe(ctx context.Context, ch chan<- *T) {
+	b.mu.Lock()
+	b.listeners[ch] = struct{}{}
+	b.mu.Unlock()
+	go func() {
+		<-ctx.Done()
+		b.mu.Lock()
+		delete(b.listeners, ch)
+		b.mu.Unlock()
+	}()
+}
+
+// Publish report, slow listeners skip the report instead of blocking the engine
+func (b *Broadcaster[T]) Publish(report *T) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for listener := range b.listeners {
+		select {
+		case listener <- report:
+		default:
+		}
+	}
+}
+
+// NewBroadcaster construct broadcaster without listeners
+func NewBroadcaster[T any]() *Broadcaster[T] {
+	return &Broadcaster[T]{listeners: map[chan<- *T]struct{}{}}
+}
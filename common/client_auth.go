@@ -0,0 +1,95 @@
+package common
+
+import "fmt"
+
+// auth strategies of clients
+const (
+	AuthBasic  = "basic"   // basic authorization by email and password of client
+	AuthLogin  = "login"   // bearer token of login endpoint requested with email and password of client
+	AuthOAuth2 = "oauth2"  // bearer token of oauth2 client credentials grant
+	AuthAPIKey = "api_key" // static key in header
+)
+
+// defaults of client auth
+const (
+	DefaultTokenPath    = "$.access_token"
+	DefaultExpiresPath  = "$.expires_in"
+	DefaultAPIKeyHeader = "X-API-Key"
+)
+
+// ClientAuth auth strategy of client, tokens are obtained on first request, cached and refreshed on expiry
+type ClientAuth struct {
+	Type string `yaml:"type"`
+	// URL of login endpoint or token endpoint of oauth2, relative url is resolved against url of client
+	URL string `yaml:"url"`
+	// Body text/template of login request over email and password of client, json with them by default
+	Body string `yaml:"body"`
+	// TokenPath JSONPath of token in response
+	TokenPath string `yaml:"token_path"`
+	// ExpiresPath JSONPath of lifetime of token in seconds, lifetime is optional
+	ExpiresPath string `yaml:"expires_path"`
+	// TTL lifetime of token when response has no lifetime,
+	// token without lifetime is kept until server responds unauthorized
+	TTL          Duration `yaml:"ttl"`
+	ClientID     string   `yaml:"client_id"`
+	ClientSecret string   `yaml:"client_secret"`
+	Scopes       []string `yaml:"scopes"`
+	// Header of api key
+	Header string `yaml:"header"`
+	Key    string `yaml:"key"`
+}
+
+// GetTokenPath return JSONPath of token
+func (a *ClientAuth) GetTokenPath() string {
+	if a.TokenPath == "" {
+		return DefaultTokenPath
+	}
+	return a.TokenPath
+}
+
+// GetExpiresPath return JSONPath of lifetime of token
+func (a *ClientAuth) GetExpiresPath() string {
+	if a.ExpiresPath == "" {
+		return DefaultExpiresPath
+	}
+	return a.ExpiresPath
+}
+
+// GetHeader return header of api key
+func (a *ClientAuth) GetHeader() string {
+	if a.Header == "" {
+		return DefaultAPIKeyHeader
+	}
+	return a.Header
+}
+
+// Validate auth of client, email and password are taken from client
+func (a *ClientAuth) Validate(client *Client) error {
+	invalid := func(reason string) error {
+		return ErrInvalidConfig(fmt.Sprintf("auth %s of client %s %s", a.Type, client.Name, reason))
+	}
+	switch a.Type {
+	case AuthBasic:
+		if client.Email == "" {
+			return invalid("requires email")
+		}
+	case AuthLogin:
+		if a.URL == "" {
+			return invalid("requires url")
+		}
+		if a.Body == "" && client.Email == "" {
+			return invalid("requires email or body")
+		}
+	case AuthOAuth2:
+		if a.URL == "" || a.ClientID == "" {
+			return invalid("requires url and client_id")
+		}
+	case AuthAPIKey:
+		if a.Key == "" {
+			return invalid("requires key")
+		}
+	default:
+		return invalid("is unknown")
+	}
+	return nil
+}
@@ -1,9 +1,55 @@
 package common
 
+import "fmt"
+
 // Client config
 type Client struct {
-	Name     string `yaml:"name"` // unique name of client
-	Url      string `yaml:"url"`
-	Email    string `yaml:"email"`
-	Password string `yaml:"password"`
+	Name string `yaml:"name"` // unique name of client
+	// Type kind of client in client factory registry: http, grpc, s3 or registered custom kind, http by default
+	Type     string            `yaml:"type"`
+	Url      string            `yaml:"url"`
+	Email    string            `yaml:"email"`
+	Password string            `yaml:"password"`
+	Headers  map[string]string `yaml:"headers"`
+	Timeout  Duration          `yaml:"timeout"`
+	TLS      *ClientTLS        `yaml:"tls"`
+	// Token static bearer token of requests
+	Token string      `yaml:"token"`
+	Auth  *ClientAuth `yaml:"auth"`
+	// Extra fields of client for custom kinds and s3 keys, they are under own key, so misspelled
+	// fields of client are still reported as unknown keys
+	Extra map[string]interface{} `yaml:"extra"`
+}
+
+// ClientTLS tls settings of client, files are in PEM format
+type ClientTLS struct {
+	CAFile             string `yaml:"ca_file"`
+	CertFile           string `yaml:"cert_file"`
+	KeyFile            string `yaml:"key_file"`
+	ServerName         string `yaml:"server_name"`
+	InsecureSkipVerify bool   `yaml:"insecure_skip_verify"`
+}
+
+// GetType return kind of client, http by default
+func (c *Client) GetType() string {
+	if c.Type == "" {
+		return ClientHTTP
+	}
+	return c.Type
+}
+
+// ExtraString return extra field of client as string, empty if it is absent
+func (c *Client) ExtraString(key string) string {
+	value, ok := c.Extra[key]
+	if !ok {
+		return ""
+	}
+	return fmt.Sprint(value)
 }
+
+// kinds of bundled clients
+const (
+	ClientHTTP = "http"
+	ClientGRPC = "grpc"
+	ClientS3   = "s3"
+)
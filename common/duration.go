@@ -0,0 +1,55 @@
+package common
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Duration of stress load, in yaml it is integer count of seconds or duration string like 6h or 90m
+type Duration time.Duration
+
+// UnmarshalYAML decode integer seconds or duration string
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	if seconds, err := strconv.Atoi(value.Value); err == nil {
+		*d = Duration(time.Duration(seconds) * time.Second)
+		return nil
+	}
+	duration, err := time.ParseDuration(value.Value)
+	if err != nil {
+		return ErrInvalidConfig(fmt.Sprintf("invalid duration %s at line %d", value.Value, value.Line))
+	}
+	*d = Duration(duration)
+	return nil
+}
+
+// ParseDuration parse raw value of duration param the same way as Duration in yaml: duration string like 90m,
+// integer string or number is count of seconds. Raw params of states keep durations as duration strings.
+func ParseDuration(value interface{}) (time.Duration, error) {
+	switch v := value.(type) {
+	case time.Duration:
+		return v, nil
+	case string:
+		if seconds, err := strconv.Atoi(v); err == nil {
+			return time.Duration(seconds) * time.Second, nil
+		}
+		return time.ParseDuration(v)
+	}
+	var seconds float64
+	if err := AssignValue(&seconds, value); err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+// Duration return value as time.Duration
+func (d Duration) Duration() time.Duration {
+	return time.Duration(d)
+}
+
+// String return duration string like 6h0m0s
+func (d Duration) String() string {
+	return time.Duration(d).String()
+}
@@ -0,0 +1,41 @@
+package common
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value   interface{}
+		want    time.Duration
+		invalid bool
+	}{
+		{value: "6h", want: 6 * time.Hour},
+		{value: "1h30m0s", want: 90 * time.Minute},
+		{value: "90", want: 90 * time.Second},
+		{value: 90, want: 90 * time.Second},
+		{value: 0.5, want: 500 * time.Millisecond},
+		{value: json.Number("120"), want: 2 * time.Minute},
+		{value: time.Minute, want: time.Minute},
+		{value: "1.5", invalid: true},
+		{value: true, invalid: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDuration(tt.value)
+		if tt.invalid {
+			if err == nil {
+				t.Fatalf("%v: expected error, got %s", tt.value, got)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Fatalf("%v: expected %s, got %s (%v)", tt.value, tt.want, got, err)
+		}
+		var assigned time.Duration
+		if err = AssignValue(&assigned, tt.value); err != nil || assigned != tt.want {
+			t.Fatalf("%v: expected assigned %s, got %s (%v)", tt.value, tt.want, assigned, err)
+		}
+	}
+}
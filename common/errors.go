@@ -1,6 +1,9 @@
 package common
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type errParameterRequired struct {
 	parameter string
@@ -162,3 +165,248 @@ func (e *errRequiredFieldDidntSet) Error() string {
 		"config is invalid, required field %s, didn't found in scenario %s, method %s",
 		e.requiredField, e.scenario, e.method)
 }
+
+type errInvalidThreshold struct {
+	expr, reason string
+}
+
+// ErrInvalidThreshold error
+func ErrInvalidThreshold(expr, reason string) error {
+	return &errInvalidThreshold{expr: expr, reason: reason}
+}
+
+// Error return error string
+func (e *errInvalidThreshold) Error() string {
+	return fmt.Sprintf("invalid threshold %q: %s", e.expr, e.reason)
+}
+
+type errThresholdsBreached struct {
+	breaches []string
+}
+
+// ErrThresholdsBreached error
+func ErrThresholdsBreached(breaches []string) error {
+	return &errThresholdsBreached{breaches: breaches}
+}
+
+// Error return error string
+func (e *errThresholdsBreached) Error() string {
+	return fmt.Sprintf("stress thresholds breached: %s", strings.Join(e.breaches, "; "))
+}
+
+type errStressAborted struct {
+	reason string
+}
+
+// ErrStressAborted error
+func ErrStressAborted(reason string) error {
+	return &errStressAborted{reason: reason}
+}
+
+// Error return error string
+func (e *errStressAborted) Error() string {
+	return fmt.Sprintf("stress load aborted: %s", e.reason)
+}
+
+type errUnknownLaunch struct {
+	id string
+}
+
+// ErrUnknownLaunch error
+func ErrUnknownLaunch(id string) error {
+	return &errUnknownLaunch{id: id}
+}
+
+// Error return error string
+func (e *errUnknownLaunch) Error() string {
+	return fmt.Sprintf("unknown launch %s", e.id)
+}
+
+type errUnexpectedStatus struct {
+	method string
+	status int
+	body   string
+}
+
+// ErrUnexpectedStatus error
+func ErrUnexpectedStatus(method string, status int, body string) error {
+	return &errUnexpectedStatus{method: method, status: status, body: body}
+}
+
+// Error return error string
+func (e *errUnexpectedStatus) Error() string {
+	return fmt.Sprintf("method %s got unexpected status %d: %s", e.method, e.status, e.body)
+}
+
+type errExtractFailed struct {
+	method, field, path, reason string
+}
+
+// ErrExtractFailed error
+func ErrExtractFailed(method, field, path, reason string) error {
+	return &errExtractFailed{method: method, field: field, path: path, reason: reason}
+}
+
+// Error return error string
+func (e *errExtractFailed) Error() string {
+	return fmt.Sprintf("method %s cannot extract field %s by %s: %s", e.method, e.field, e.path, e.reason)
+}
+
+type errUnexpectedCode struct {
+	method, code, message string
+}
+
+// ErrUnexpectedCode error
+func ErrUnexpectedCode(method, code, message string) error {
+	return &errUnexpectedCode{method: method, code: code, message: message}
+}
+
+// Error return error string
+func (e *errUnexpectedCode) Error() string {
+	return fmt.Sprintf("method %s got unexpected code %s: %s", e.method, e.code, e.message)
+}
+
+type errChecksumMismatch struct {
+	object, expected, actual string
+}
+
+// ErrChecksumMismatch error
+func ErrChecksumMismatch(object, expected, actual string) error {
+	return &errChecksumMismatch{object: object, expected: expected, actual: actual}
+}
+
+// Error return error string
+func (e *errChecksumMismatch) Error() string {
+	return fmt.Sprintf("checksum of %s mismatch, expected %s, actual %s", e.object, e.expected, e.actual)
+}
+
+type errUnknownClient struct {
+	name string
+}
+
+// ErrUnknownClient error
+func ErrUnknownClient(name string) error {
+	return &errUnknownClient{name: name}
+}
+
+// Error return error string
+func (e *errUnknownClient) Error() string {
+	return fmt.Sprintf("unknown client %s", e.name)
+}
+
+type errUnknownClientKind struct {
+	client, kind string
+}
+
+// ErrUnknownClientKind error
+func ErrUnknownClientKind(client, kind string) error {
+	return &errUnknownClientKind{client: client, kind: kind}
+}
+
+// Error return error string
+func (e *errUnknownClientKind) Error() string {
+	return fmt.Sprintf("client %s has unknown kind %s", e.client, e.kind)
+}
+
+type errClientHasIncorrectType struct {
+	client, expectedType string
+}
+
+// ErrClientHasIncorrectType error
+func ErrClientHasIncorrectType(client, expectedType string) error {
+	return &errClientHasIncorrectType{client: client, expectedType: expectedType}
+}
+
+// Error return error string
+func (e *errClientHasIncorrectType) Error() string {
+	return fmt.Sprintf("client %s has incorrect type, expected %s", e.client, e.expectedType)
+}
+
+type errAuthFailed struct {
+	client, reason string
+}
+
+// ErrAuthFailed error
+func ErrAuthFailed(client, reason string) error {
+	return &errAuthFailed{client: client, reason: reason}
+}
+
+// Error return error string
+func (e *errAuthFailed) Error() string {
+	return fmt.Sprintf("auth of client %s failed: %s", e.client, e.reason)
+}
+
+type errSecretNotResolved struct {
+	ref, reason string
+}
+
+// ErrSecretNotResolved error
+func ErrSecretNotResolved(ref, reason string) error {
+	return &errSecretNotResolved{ref: ref, reason: reason}
+}
+
+// Error return error string
+func (e *errSecretNotResolved) Error() string {
+	return fmt.Sprintf("secret %s isn't resolved: %s", e.ref, e.reason)
+}
+
+type errConfigIssues struct {
+	issues []string
+}
+
+// ErrConfigIssues error of all issues found in config, issues are prefixed with file and line
+func ErrConfigIssues(issues []string) error {
+	return &errConfigIssues{issues: issues}
+}
+
+// Error return error string
+func (e *errConfigIssues) Error() string {
+	return fmt.Sprintf("config has %d issues:\n\t%s", len(e.issues), strings.Join(e.issues, "\n\t"))
+}
+
+// Issues return issues of config
+func (e *errConfigIssues) Issues() []string {
+	return e.issues
+}
+
+type errReloadDisabled struct{}
+
+// ErrReloadDisabled error
+func ErrReloadDisabled() error {
+	return &errReloadDisabled{}
+}
+
+// Error return error string
+func (e *errReloadDisabled) Error() string {
+	return "reload of config is disabled"
+}
+
+type errPlugin struct {
+	plugin string
+	reason string
+}
+
+// ErrPlugin error
+func ErrPlugin(plugin, reason string) error {
+	return &errPlugin{plugin: plugin, reason: reason}
+}
+
+// Error return error string
+func (e *errPlugin) Error() string {
+	return fmt.Sprintf("plugin %s: %s", e.plugin, e.reason)
+}
+
+type errScriptFailed struct {
+	tester string
+	reason string
+}
+
+// ErrScriptFailed error
+func ErrScriptFailed(tester, reason string) error {
+	return &errScriptFailed{tester: tester, reason: reason}
+}
+
+// Error return error string
+func (e *errScriptFailed) Error() string {
+	return fmt.Sprintf("script tester %s failed: %s", e.tester, e.reason)
+}
@@ -0,0 +1,95 @@
+package common
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultGRPCTesterTimeout default timeout of call of declarative grpc tester
+const DefaultGRPCTesterTimeout = 30 * time.Second
+
+// GRPCTester config of declarative grpc tester, request and metadata are text/template
+// templates over raw params of state, like {"id": {{json .user_id}}}
+type GRPCTester struct {
+	Name string `yaml:"name"` // method name of tester in testers pool
+	// Description of tester for scenario authors, grpc method by default
+	Description string `yaml:"description"`
+	// Method full name of grpc method, like package.Service/Method
+	Method string `yaml:"method"`
+	// ProtoFiles, DescriptorSet or Reflection is source of descriptors of method, relative paths are resolved
+	// against directory of config, proto files are relative to import paths if they are set
+	ProtoFiles    []string `yaml:"proto_files"`
+	ImportPaths   []string `yaml:"import_paths"`
+	DescriptorSet string   `yaml:"descriptor_set"`
+	Reflection    bool     `yaml:"reflection"`
+	// Request json template of request message
+	Request  string            `yaml:"request"`
+	Metadata map[string]string `yaml:"metadata"`
+	// ExpectedCode grpc status code name, like NotFound, OK by default
+	ExpectedCode string        `yaml:"expected_code"`
+	Timeout      time.Duration `yaml:"timeout"`
+	// Required fields of state used in templates
+	Required []string `yaml:"required"`
+	// Extract returned fields of state from json representation of response by JSONPath, like $.user.id
+	Extract map[string]string `yaml:"extract"`
+}
+
+// GetDescription return description of tester, grpc method by default
+func (t *GRPCTester) GetDescription() string {
+	if t.Description == "" {
+		return "grpc " + t.Method
+	}
+	return t.Description
+}
+
+// GetTimeout return timeout of call
+func (t *GRPCTester) GetTimeout() time.Duration {
+	if t.Timeout <= 0 {
+		return DefaultGRPCTesterTimeout
+	}
+	return t.Timeout
+}
+
+// GetRequest return request template, empty message by default
+func (t *GRPCTester) GetRequest() string {
+	if t.Request == "" {
+		return "{}"
+	}
+	return t.Request
+}
+
+// Service return full name of service and name of method
+func (t *GRPCTester) Service() (service, method string) {
+	name := strings.TrimPrefix(t.Method, "/")
+	i := strings.LastIndexAny(name, "/.")
+	if i < 0 {
+		return "", name
+	}
+	return name[:i], name[i+1:]
+}
+
+// Validate grpc tester config
+func (t *GRPCTester) Validate() error {
+	if t.Name == "" {
+		return ErrInvalidConfig("grpc tester without name")
+	}
+	if service, method := t.Service(); service == "" || method == "" {
+		return ErrInvalidConfig(fmt.Sprintf("grpc tester %s has invalid method %s", t.Name, t.Method))
+	}
+	sources := 0
+	if len(t.ProtoFiles) > 0 {
+		sources++
+	}
+	if t.DescriptorSet != "" {
+		sources++
+	}
+	if t.Reflection {
+		sources++
+	}
+	if sources != 1 {
+		return ErrInvalidConfig(fmt.Sprintf(
+			"grpc tester %s requires exactly one of proto_files, descriptor_set or reflection", t.Name))
+	}
+	return nil
+}
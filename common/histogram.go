@@ -0,0 +1,119 @@
+package common
+
+import (
+	"math"
+	"math/bits"
+	"time"
+)
+
+const (
+	// histogramLinear count of buckets with exact microseconds
+	histogramLinear = 1024
+	// histogramSubBuckets count of buckets in every power of two above linear part
+	histogramSubBuckets = histogramLinear / 2
+	// histogramExponents count of powers of two above linear part, longer latencies fall into last bucket
+	histogramExponents = 32
+)
+
+// Histogram of latencies with constant memory, latencies below 1ms are exact up to microsecond,
+// above they are stored with relative error less than 0.2%
+type Histogram struct {
+	counts [histogramLinear + histogramExponents*histogramSubBuckets]uint64
+	count  int
+	sum    time.Duration
+	max    time.Duration
+}
+
+// NewHistogram construct empty Histogram
+func NewHistogram() *Histogram {
+	return &Histogram{}
+}
+
+// Add latency to histogram
+func (h *Histogram) Add(latency time.Duration) {
+	if latency < 0 {
+		latency = 0
+	}
+	h.counts[histogramIndex(latency)]++
+	h.count++
+	h.sum += latency
+	if latency > h.max {
+		h.max = latency
+	}
+}
+
+// Merge add all latencies of other histogram
+func (h *Histogram) Merge(other *Histogram) {
+	for i, count := range other.counts {
+		h.counts[i] += count
+	}
+	h.count += other.count
+	h.sum += other.sum
+	if other.max > h.max {
+		h.max = other.max
+	}
+}
+
+// Count return count of latencies
+func (h *Histogram) Count() int {
+	return h.count
+}
+
+// Percentile return latency of percentile p (0-100)
+func (h *Histogram) Percentile(p float64) time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	rank := uint64(math.Ceil(p / 100 * float64(h.count)))
+	if rank == 0 {
+		rank = 1
+	}
+	var seen uint64
+	for i, count := range h.counts {
+		seen += count
+		if seen >= rank {
+			if latency := histogramValue(i); latency < h.max {
+				return latency
+			}
+			return h.max
+		}
+	}
+	return h.max
+}
+
+// Avg return average latency
+func (h *Histogram) Avg() time.Duration {
+	if h.count == 0 {
+		return 0
+	}
+	return h.sum / time.Duration(h.count)
+}
+
+// Max return max latency
+func (h *Histogram) Max() time.Duration {
+	return h.max
+}
+
+func histogramIndex(latency time.Duration) int {
+	us := uint64(latency / time.Microsecond)
+	if us < histogramLinear {
+		return int(us)
+	}
+	shift := bits.Len64(us) - bits.Len64(histogramLinear-1)
+	if shift > histogramExponents {
+		return histogramLinear + histogramExponents*histogramSubBuckets - 1
+	}
+	sub := int(us>>shift) - histogramSubBuckets
+	return histogramLinear + (shift-1)*histogramSubBuckets + sub
+}
+
+// histogramValue return upper bound of bucket
+func histogramValue(index int) time.Duration {
+	if index < histogramLinear {
+		return time.Duration(index) * time.Microsecond
+	}
+	index -= histogramLinear
+	shift := index/histogramSubBuckets + 1
+	sub := uint64(index%histogramSubBuckets + histogramSubBuckets)
+	return time.Duration(((sub+1)<<shift)-1) * time.Microsecond
+}
@@ -0,0 +1,74 @@
+package common
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultHTTPTesterTimeout default timeout of request of declarative http tester
+const DefaultHTTPTesterTimeout = 30 * time.Second
+
+// HTTPTester config of declarative http tester, url, headers and body are text/template
+// templates over raw params of state, like {{.user_id}}
+type HTTPTester struct {
+	Name string `yaml:"name"` // method name of tester in testers pool
+	// Description of tester for scenario authors, method and url by default
+	Description string `yaml:"description"`
+	Method      string `yaml:"method"`
+	// URL relative url is resolved against url of stage client
+	URL     string            `yaml:"url"`
+	Headers map[string]string `yaml:"headers"`
+	Body    string            `yaml:"body"`
+	// ExpectedStatus any 2xx status is expected by default
+	ExpectedStatus int           `yaml:"expected_status"`
+	Timeout        time.Duration `yaml:"timeout"`
+	// Required fields of state used in templates
+	Required []string `yaml:"required"`
+	// Extract returned fields of state from json response by JSONPath, like $.data.items[0].id
+	Extract map[string]string `yaml:"extract"`
+}
+
+// GetMethod return http method, GET by default
+func (t *HTTPTester) GetMethod() string {
+	if t.Method == "" {
+		return http.MethodGet
+	}
+	return strings.ToUpper(t.Method)
+}
+
+// GetDescription return description of tester, method and url by default
+func (t *HTTPTester) GetDescription() string {
+	if t.Description == "" {
+		return t.GetMethod() + " " + t.URL
+	}
+	return t.Description
+}
+
+// GetTimeout return timeout of request
+func (t *HTTPTester) GetTimeout() time.Duration {
+	if t.Timeout <= 0 {
+		return DefaultHTTPTesterTimeout
+	}
+	return t.Timeout
+}
+
+// CheckStatus return true if status of response is expected
+func (t *HTTPTester) CheckStatus(status int) bool {
+	if t.ExpectedStatus == 0 {
+		return status >= http.StatusOK && status < http.StatusMultipleChoices
+	}
+	return status == t.ExpectedStatus
+}
+
+// Validate http tester config
+func (t *HTTPTester) Validate() error {
+	if t.Name == "" {
+		return ErrInvalidConfig("http tester without name")
+	}
+	if t.URL == "" {
+		return ErrInvalidConfig(fmt.Sprintf("http tester %s without url", t.Name))
+	}
+	return nil
+}
@@ -0,0 +1,143 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NormalizeNumbers restore json numbers of raw params as int if it is possible or float64,
+// because generated state expects the same types as in yaml config
+func NormalizeNumbers(params map[string]interface{}) {
+	for key, value := range params {
+		number, ok := value.(json.Number)
+		if !ok {
+			continue
+		}
+		if i, err := strconv.Atoi(number.String()); err == nil {
+			params[key] = i
+			continue
+		}
+		params[key], _ = number.Float64()
+	}
+}
+
+// AssignValue convert raw value to type of target pointer and assign it, raw values come from
+// decoded json or yaml: numbers, strings, bools, slices and maps
+func AssignValue(target interface{}, value interface{}) error {
+	if value == nil {
+		return nil
+	}
+	dst := reflect.ValueOf(target).Elem()
+	src := reflect.ValueOf(value)
+	if src.Type().AssignableTo(dst.Type()) {
+		dst.Set(src)
+		return nil
+	}
+	switch dst.Interface().(type) {
+	case time.Duration:
+		return assignDuration(dst, value)
+	case time.Time:
+		if s, ok := value.(string); ok {
+			t, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return err
+			}
+			dst.Set(reflect.ValueOf(t))
+			return nil
+		}
+	}
+	switch dst.Kind() {
+	case reflect.String:
+		switch src.Kind() {
+		case reflect.Map, reflect.Slice, reflect.Struct:
+		default:
+			dst.SetString(fmt.Sprint(value))
+			return nil
+		}
+	case reflect.Bool:
+		if s, ok := value.(string); ok {
+			b, err := strconv.ParseBool(s)
+			if err != nil {
+				return err
+			}
+			dst.SetBool(b)
+			return nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return assignNumber(dst, value)
+	case reflect.Slice:
+		if s, ok := value.(string); ok && dst.Type().Elem().Kind() == reflect.String {
+			value = strings.Split(s, ",")
+		}
+	}
+	// composite values are converted through json
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, dst.Addr().Interface())
+}
+
+// assignDuration parse duration string or count of seconds
+func assignDuration(dst reflect.Value, value interface{}) error {
+	d, err := ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	dst.SetInt(int64(d))
+	return nil
+}
+
+// assignNumber convert number or numeric string to numeric kind of dst
+func assignNumber(dst reflect.Value, value interface{}) error {
+	var s string
+	switch v := value.(type) {
+	case string:
+		s = v
+	case json.Number:
+		s = v.String()
+	case bool:
+		return fmt.Errorf("cannot convert bool to %s", dst.Type())
+	default:
+		src := reflect.ValueOf(value)
+		if !src.Type().ConvertibleTo(dst.Type()) {
+			return fmt.Errorf("cannot convert %T to %s", value, dst.Type())
+		}
+		converted := src.Convert(dst.Type())
+		if src.Kind() == reflect.Float32 || src.Kind() == reflect.Float64 {
+			if dst.Kind() != reflect.Float32 && dst.Kind() != reflect.Float64 && src.Float() != math.Trunc(src.Float()) {
+				return fmt.Errorf("cannot convert fractional number %v to %s", value, dst.Type())
+			}
+		}
+		dst.Set(converted)
+		return nil
+	}
+	switch dst.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, dst.Type().Bits())
+		if err != nil {
+			return err
+		}
+		dst.SetFloat(f)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, dst.Type().Bits())
+		if err != nil {
+			return err
+		}
+		dst.SetUint(u)
+	default:
+		i, err := strconv.ParseInt(s, 10, dst.Type().Bits())
+		if err != nil {
+			return err
+		}
+		dst.SetInt(i)
+	}
+	return nil
+}
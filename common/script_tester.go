@@ -0,0 +1,56 @@
+package common
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultScriptTesterTimeout default timeout of run of script tester
+const DefaultScriptTesterTimeout = 30 * time.Second
+
+// ScriptTester config of tester written in Starlark, script defines function run(ctx), which reads
+// state by ctx.get, sets returned fields by ctx.set or returns them in dict. Modules http, json, time,
+// crypto and assert are predeclared, http requests use client of stage by default.
+type ScriptTester struct {
+	Name string `yaml:"name"` // method name of tester in testers pool
+	// Description of tester for scenario authors
+	Description string `yaml:"description"`
+	// Script source of script, File is path of file with source instead
+	Script  string        `yaml:"script"`
+	File    string        `yaml:"file"`
+	Timeout time.Duration `yaml:"timeout"`
+	// Required fields of state read by script
+	Required []string `yaml:"required"`
+	// Returned fields of state set by script
+	Returned []string `yaml:"returned"`
+}
+
+// GetDescription return description of tester, script file by default
+func (t *ScriptTester) GetDescription() string {
+	switch {
+	case t.Description != "":
+		return t.Description
+	case t.File != "":
+		return "script " + t.File
+	}
+	return "inline script"
+}
+
+// GetTimeout return timeout of run
+func (t *ScriptTester) GetTimeout() time.Duration {
+	if t.Timeout <= 0 {
+		return DefaultScriptTesterTimeout
+	}
+	return t.Timeout
+}
+
+// Validate script tester config
+func (t *ScriptTester) Validate() error {
+	if t.Name == "" {
+		return ErrInvalidConfig("script tester without name")
+	}
+	if (t.Script == "") == (t.File == "") {
+		return ErrInvalidConfig(fmt.Sprintf("script tester %s must have either script or file", t.Name))
+	}
+	return nil
+}
@@ -1,16 +1,186 @@
 package common
 
-// StressLoad type for describe stress load params
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	// ModelOpen rate driven load, requests are scheduled by rps from and to
+	ModelOpen = "open"
+	// ModelClosed fixed count of virtual users loop on action with think time
+	ModelClosed = "closed"
+
+	// AmmoFinish stop shooting when ammo is exhausted
+	AmmoFinish = "finish"
+	// AmmoCycle shoot the same ammo again when it is exhausted
+	AmmoCycle = "cycle"
+	// AmmoGenerate generate fresh ammo when prepared ammo is exhausted
+	AmmoGenerate = "generate"
+)
+
+// StressLoad type for describe stress load params, duration is integer seconds or duration string like 6h
 type StressLoad struct {
 	Instances  int
-	Duration   int
+	Duration   Duration
 	Type       string
 	From       int
 	To         int
-	ShootCount int `yaml:"-"`
+	Model      string
+	ThinkTime  time.Duration `yaml:"think_time"`
+	Thresholds []string
+	// thresholds parsed by validation of thresholds
+	thresholds []Threshold
+	Abort      *AbortCriteria
+	Streaming  *Streaming
+	Soak       *Soak
+	// OnAmmoExhausted behaviour of exhausted ammo: finish (default), cycle or generate
+	OnAmmoExhausted string `yaml:"on_ammo_exhausted"`
+	ShootCount      int    `yaml:"-"`
+}
+
+// Streaming lazy generation of stress load ammo with constant memory
+type Streaming struct {
+	// Slots size of ring of states, DefaultStreamingSlots by default
+	Slots int `yaml:"slots"`
+	// DataFile json lines file with raw params for every shot
+	DataFile string `yaml:"data_file"`
+}
+
+// Soak long stress load with periodic checkpoints of latency, errors and memory of runner
+type Soak struct {
+	// Checkpoint interval between checkpoints, DefaultSoakCheckpoint by default
+	Checkpoint time.Duration `yaml:"checkpoint"`
+}
+
+// DefaultSoakCheckpoint default interval between checkpoints of soak load
+const DefaultSoakCheckpoint = 10 * time.Minute
+
+// GetCheckpoint return interval between checkpoints
+func (s *Soak) GetCheckpoint() time.Duration {
+	if s.Checkpoint <= 0 {
+		return DefaultSoakCheckpoint
+	}
+	return s.Checkpoint
+}
+
+// DefaultStreamingSlots default size of ring of states
+const DefaultStreamingSlots = 1024
+
+// GetSlots return size of ring of states, load without streaming config, like generated ammo after prepared one,
+// uses default size
+func (s *Streaming) GetSlots() int {
+	if s == nil || s.Slots <= 0 {
+		return DefaultStreamingSlots
+	}
+	return s.Slots
 }
 
 // GetShootCount return correct shoot count
 func (s *StressLoad) GetShootCount() int {
 	return s.ShootCount
 }
+
+// IsStreaming return true if ammo is generated lazily
+func (s *StressLoad) IsStreaming() bool {
+	return s.Streaming != nil
+}
+
+// IsSoak return true if stress load emits periodic checkpoints
+func (s *StressLoad) IsSoak() bool {
+	return s.Soak != nil
+}
+
+// IsClosed return true for closed load model
+func (s *StressLoad) IsClosed() bool {
+	return s.Model == ModelClosed
+}
+
+// TargetRPS return average rps planned by schedule, for closed model it is rps of users without latency
+func (s *StressLoad) TargetRPS() float64 {
+	if s.IsClosed() {
+		return float64(s.Instances) / s.ThinkTime.Seconds()
+	}
+	return float64(s.From+s.To) / 2
+}
+
+// Validate stress load params
+func (s *StressLoad) Validate() error {
+	if err := s.ValidateModel(); err != nil {
+		return err
+	}
+	if s.Duration <= 0 {
+		return ErrInvalidConfig("stress load requires positive duration")
+	}
+	switch s.OnAmmoExhausted {
+	case "", AmmoFinish, AmmoCycle, AmmoGenerate:
+	default:
+		return ErrInvalidConfig(fmt.Sprintf("unknown on_ammo_exhausted behaviour %s", s.OnAmmoExhausted))
+	}
+	if s.Abort != nil {
+		if err := s.Abort.Validate(); err != nil {
+			return err
+		}
+	}
+	return s.ValidateThresholds()
+}
+
+// AmmoExhausted return behaviour of exhausted ammo
+func (s *StressLoad) AmmoExhausted() string {
+	if s.OnAmmoExhausted == "" {
+		return AmmoFinish
+	}
+	return s.OnAmmoExhausted
+}
+
+// ValidateModel check load model params
+func (s *StressLoad) ValidateModel() error {
+	switch s.Model {
+	case "", ModelOpen:
+		return nil
+	case ModelClosed:
+		if s.Instances <= 0 {
+			return ErrInvalidConfig("closed model requires positive instances")
+		}
+		if s.ThinkTime <= 0 {
+			return ErrInvalidConfig("closed model requires positive think_time")
+		}
+		return nil
+	default:
+		return ErrInvalidConfig(fmt.Sprintf("unknown load model %s", s.Model))
+	}
+}
+
+// ValidateThresholds check that all thresholds can be parsed and keep parsed thresholds for checks
+func (s *StressLoad) ValidateThresholds() error {
+	thresholds := make([]Threshold, len(s.Thresholds))
+	for i, expr := range s.Thresholds {
+		threshold, err := ParseThreshold(expr)
+		if err != nil {
+			return err
+		}
+		thresholds[i] = threshold
+	}
+	s.thresholds = thresholds
+	return nil
+}
+
+// CheckThresholds check summary of stress load, return error with all breached thresholds.
+// Thresholds are parsed once, stress load without validation is validated on first check.
+func (s *StressLoad) CheckThresholds(summary *Summary) error {
+	if len(s.thresholds) != len(s.Thresholds) {
+		if err := s.ValidateThresholds(); err != nil {
+			return err
+		}
+	}
+	breaches := make([]string, 0)
+	for _, threshold := range s.thresholds {
+		if actual, ok := threshold.Check(summary, s.TargetRPS()); !ok {
+			breaches = append(breaches, fmt.Sprintf("%s (actual %s)", threshold, actual))
+		}
+	}
+	if len(breaches) > 0 {
+		return ErrThresholdsBreached(breaches)
+	}
+	return nil
+}
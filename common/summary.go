@@ -0,0 +1,56 @@
+package common
+
+import (
+	"time"
+)
+
+// Summary aggregated results of stress load
+type Summary struct {
+	Requests int
+	Errors   int
+	// Dropped samples of requests lost by aggregator, they are counted as errors in error rate
+	Dropped   int
+	Elapsed   time.Duration
+	latencies *Histogram
+}
+
+// NewSummary construct Summary from histogram of collected latencies
+func NewSummary(latencies *Histogram, errors int, elapsed time.Duration) *Summary {
+	return &Summary{
+		Requests:  latencies.Count(),
+		Errors:    errors,
+		Elapsed:   elapsed,
+		latencies: latencies,
+	}
+}
+
+// Percentile return latency of percentile p (0-100)
+func (s *Summary) Percentile(p float64) time.Duration {
+	return s.latencies.Percentile(p)
+}
+
+// Avg return average latency
+func (s *Summary) Avg() time.Duration {
+	return s.latencies.Avg()
+}
+
+// Max return max latency
+func (s *Summary) Max() time.Duration {
+	return s.latencies.Max()
+}
+
+// ErrorRate return part of failed requests (0-1), dropped samples are failed requests
+func (s *Summary) ErrorRate() float64 {
+	if s.Requests+s.Dropped == 0 {
+		return 0
+	}
+	return float64(s.Errors+s.Dropped) / float64(s.Requests+s.Dropped)
+}
+
+// RPS return average requests per second
+func (s *Summary) RPS() float64 {
+	if s.Elapsed <= 0 {
+		return 0
+	}
+	return float64(s.Requests) / s.Elapsed.Seconds()
+}
@@ -0,0 +1,115 @@
+package common
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	metricAvg       = "avg"
+	metricMax       = "max"
+	metricErrorRate = "error_rate"
+	metricRPS       = "rps"
+	targetSuffix    = "*target"
+)
+
+var thresholdRegexp = regexp.MustCompile(`^\s*([a-z_0-9.]+)\s*(<=|>=|<|>)\s*(\S+)\s*$`)
+
+// Threshold parsed service level objective of stress load, for example "p99 < 200ms",
+// "error_rate < 1%" or "rps >= 0.95*target"
+type Threshold struct {
+	Metric   string
+	Operator string
+	// Value in metric units: nanoseconds for latency, part (0-1) for error rate, requests per second for rps
+	Value float64
+	// Target value is a multiplier of target rps
+	Target bool
+	raw    string
+}
+
+// ParseThreshold parse threshold expression
+func ParseThreshold(expr string) (t Threshold, err error) {
+	matches := thresholdRegexp.FindStringSubmatch(expr)
+	if matches == nil {
+		return t, ErrInvalidThreshold(expr, "expected format \"<metric> <operator> <value>\"")
+	}
+	t = Threshold{Metric: matches[1], Operator: matches[2], raw: strings.TrimSpace(expr)}
+	value := matches[3]
+	switch {
+	case t.Metric == metricAvg || t.Metric == metricMax || isPercentile(t.Metric):
+		var d time.Duration
+		if d, err = time.ParseDuration(value); err != nil {
+			return t, ErrInvalidThreshold(expr, "latency must be a duration")
+		}
+		t.Value = float64(d)
+	case t.Metric == metricErrorRate:
+		if t.Value, err = parseRate(value); err != nil {
+			return t, ErrInvalidThreshold(expr, "error rate must be a number or percent")
+		}
+	case t.Metric == metricRPS:
+		if strings.HasSuffix(value, targetSuffix) {
+			t.Target = true
+			value = strings.TrimSuffix(value, targetSuffix)
+		}
+		if t.Value, err = strconv.ParseFloat(value, 64); err != nil {
+			return t, ErrInvalidThreshold(expr, "rps must be a number or multiplier of target")
+		}
+	default:
+		return t, ErrInvalidThreshold(expr, fmt.Sprintf("unknown metric %s", t.Metric))
+	}
+	return t, nil
+}
+
+// String return source expression
+func (t Threshold) String() string {
+	return t.raw
+}
+
+// Check threshold on summary, targetRPS used for relative rps thresholds
+func (t Threshold) Check(summary *Summary, targetRPS float64) (actual string, ok bool) {
+	var value float64
+	switch {
+	case t.Metric == metricAvg:
+		value = float64(summary.Avg())
+		actual = summary.Avg().String()
+	case t.Metric == metricMax:
+		value = float64(summary.Max())
+		actual = summary.Max().String()
+	case isPercentile(t.Metric):
+		p, _ := strconv.ParseFloat(t.Metric[1:], 64)
+		value = float64(summary.Percentile(p))
+		actual = summary.Percentile(p).String()
+	case t.Metric == metricErrorRate:
+		value = summary.ErrorRate()
+		actual = strconv.FormatFloat(value*100, 'f', 2, 64) + "%"
+	case t.Metric == metricRPS:
+		value = summary.RPS()
+		actual = strconv.FormatFloat(value, 'f', 2, 64)
+	}
+	limit := t.Value
+	if t.Target {
+		limit *= targetRPS
+	}
+	switch t.Operator {
+	case "<":
+		ok = value < limit
+	case "<=":
+		ok = value <= limit
+	case ">":
+		ok = value > limit
+	case ">=":
+		ok = value >= limit
+	}
+	return
+}
+
+func isPercentile(metric string) bool {
+	if len(metric) < 2 || metric[0] != 'p' {
+		return false
+	}
+	p, err := strconv.ParseFloat(metric[1:], 64)
+	return err == nil && p > 0 && p <= 100
+}
@@ -0,0 +1,144 @@
+package common
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestSummary summary of 100 requests with latencies 10µs..1ms, errors of them and elapsed time
+func newTestSummary(errors int, elapsed time.Duration) *Summary {
+	latencies := NewHistogram()
+	for i := 1; i <= 100; i++ {
+		latencies.Add(time.Duration(i) * 10 * time.Microsecond)
+	}
+	return NewSummary(latencies, errors, elapsed)
+}
+
+func TestParseThreshold(t *testing.T) {
+	tests := []struct {
+		expr     string
+		metric   string
+		operator string
+		value    float64
+		target   bool
+	}{
+		{expr: "p99 < 200ms", metric: "p99", operator: "<", value: float64(200 * time.Millisecond)},
+		{expr: " p99.9<=1s ", metric: "p99.9", operator: "<=", value: float64(time.Second)},
+		{expr: "avg > 1ms", metric: "avg", operator: ">", value: float64(time.Millisecond)},
+		{expr: "max >= 2s", metric: "max", operator: ">=", value: float64(2 * time.Second)},
+		{expr: "error_rate < 1%", metric: "error_rate", operator: "<", value: 0.01},
+		{expr: "error_rate <= 0.05", metric: "error_rate", operator: "<=", value: 0.05},
+		{expr: "rps >= 100", metric: "rps", operator: ">=", value: 100},
+		{expr: "rps >= 0.95*target", metric: "rps", operator: ">=", value: 0.95, target: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			threshold, err := ParseThreshold(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if threshold.Metric != tt.metric || threshold.Operator != tt.operator ||
+				threshold.Value != tt.value || threshold.Target != tt.target {
+				t.Fatalf("unexpected threshold %+v", threshold)
+			}
+			if threshold.String() != strings.TrimSpace(tt.expr) {
+				t.Fatalf("unexpected source %q", threshold.String())
+			}
+		})
+	}
+}
+
+func TestParseInvalidThreshold(t *testing.T) {
+	tests := []struct {
+		expr   string
+		reason string
+	}{
+		{expr: "p99", reason: "expected format"},
+		{expr: "p99 == 200ms", reason: "expected format"},
+		{expr: "p99 < 200", reason: "latency must be a duration"},
+		{expr: "p0 < 200ms", reason: "unknown metric p0"},
+		{expr: "p101 < 200ms", reason: "unknown metric p101"},
+		{expr: "error_rate < many", reason: "error rate must be a number or percent"},
+		{expr: "rps > 0.9*max", reason: "rps must be a number or multiplier of target"},
+		{expr: "median < 1s", reason: "unknown metric median"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			_, err := ParseThreshold(tt.expr)
+			if err == nil || !strings.Contains(err.Error(), tt.reason) {
+				t.Fatalf("expected error %q, got %v", tt.reason, err)
+			}
+		})
+	}
+}
+
+func TestThresholdCheck(t *testing.T) {
+	// 100 requests in 2s are 50 rps, 5 errors are 5%
+	summary := newTestSummary(5, 2*time.Second)
+	tests := []struct {
+		expr      string
+		targetRPS float64
+		actual    string
+		ok        bool
+	}{
+		{expr: "p50 <= 500µs", actual: "500µs", ok: true},
+		{expr: "p50 < 500µs", actual: "500µs", ok: false},
+		{expr: "p99 < 1ms", actual: "990µs", ok: true},
+		{expr: "p99 > 1ms", actual: "990µs", ok: false},
+		{expr: "p100 >= 1ms", actual: "1ms", ok: true},
+		{expr: "avg < 505µs", actual: "505µs", ok: false},
+		{expr: "avg <= 505µs", actual: "505µs", ok: true},
+		{expr: "max < 1ms", actual: "1ms", ok: false},
+		{expr: "error_rate < 5%", actual: "5.00%", ok: false},
+		{expr: "error_rate <= 0.05", actual: "5.00%", ok: true},
+		{expr: "rps > 40", actual: "50.00", ok: true},
+		{expr: "rps >= 0.95*target", targetRPS: 50, actual: "50.00", ok: true},
+		{expr: "rps >= 0.95*target", targetRPS: 100, actual: "50.00", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			threshold, err := ParseThreshold(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			actual, ok := threshold.Check(summary, tt.targetRPS)
+			if actual != tt.actual || ok != tt.ok {
+				t.Fatalf("expected %s %v, got %s %v", tt.actual, tt.ok, actual, ok)
+			}
+		})
+	}
+}
+
+func TestCheckThresholds(t *testing.T) {
+	load := &StressLoad{
+		Duration:   Duration(2 * time.Second),
+		From:       80,
+		To:         120,
+		Thresholds: []string{"p99 < 1ms", "error_rate < 1%", "rps >= 1*target"},
+	}
+	if err := load.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	err := load.CheckThresholds(newTestSummary(5, 2*time.Second))
+	if err == nil {
+		t.Fatal("breached thresholds aren't reported")
+	}
+	for _, breach := range []string{"error_rate < 1% (actual 5.00%)", "rps >= 1*target (actual 50.00)"} {
+		if !strings.Contains(err.Error(), breach) {
+			t.Fatalf("breach %q isn't reported in %q", breach, err)
+		}
+	}
+	if strings.Contains(err.Error(), "p99") {
+		t.Fatalf("passed threshold is reported in %q", err)
+	}
+	if err = load.CheckThresholds(newTestSummary(0, time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	// stress load without validation is checked too
+	load = &StressLoad{Thresholds: []string{"p99 <"}}
+	if err = load.CheckThresholds(newTestSummary(0, time.Second)); err == nil {
+		t.Fatal("invalid threshold isn't reported")
+	}
+}
@@ -5,10 +5,13 @@ import (
 
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/playground"
+
+	"github.com/lueurxax/e2e/pkg/graph"
 )
 
 func main() {
 	srv := handler.NewDefaultServer(nil)
+	srv.AroundResponses(graph.RedactSecrets)
 
 	http.Handle("/", playground.Handler("GraphQL playground", "/query"))
 	http.Handle("/query", srv)
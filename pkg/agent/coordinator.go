@@ -0,0 +1,333 @@
+package agent
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/log"
+	"github.com/lueurxax/e2e/pkg/models"
+)
+
+// releaseTimeout timeout of release of prepared job, it doesn't depend on context of failed run
+const releaseTimeout = 5 * time.Second
+
+// Coordinator split stress load across remote agents, it can replace pandora connector in stage processor
+type Coordinator interface {
+	Register(metrics common.Meter)
+	Start(
+		ctx context.Context,
+		client string,
+		tester models.Tester,
+		params []models.StateSelector,
+		opts *models.Options,
+	) (newParams []models.StateSelector, err error)
+	AddAgent(url string)
+	Agents() (urls []string)
+	// SubscribeOnProgress live progress of merged samples of agents
+	SubscribeOnProgress(ctx context.Context, ch chan<- *models.StressProgress)
+	// SubscribeOnCheckpoints checkpoints of soak load computed from merged samples of agents
+	SubscribeOnCheckpoints(ctx context.Context, ch chan<- *models.Checkpoint)
+}
+
+type coordinator struct {
+	mu          sync.RWMutex
+	agents      []string
+	token       string
+	state       models.StateTransfer
+	client      *http.Client
+	metrics     common.Meter
+	logger      log.Logger
+	progress    *common.Broadcaster[models.StressProgress]
+	checkpoints *common.Broadcaster[models.Checkpoint]
+}
+
+func (c *coordinator) Register(metrics common.Meter) {
+	c.metrics = metrics
+}
+
+// AddAgent register agent by base url
+func (c *coordinator) AddAgent(url string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.agents = append(c.agents, strings.TrimSuffix(url, "/"))
+}
+
+func (c *coordinator) SubscribeOnProgress(ctx context.Context, ch chan<- *models.StressProgress) {
+	c.progress.Subscribe(ctx, ch)
+}
+
+func (c *coordinator) SubscribeOnCheckpoints(ctx context.Context, ch chan<- *models.Checkpoint) {
+	c.checkpoints.Subscribe(ctx, ch)
+}
+
+// Agents list of registered agents
+func (c *coordinator) Agents() (urls []string) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	urls = make([]string, len(c.agents))
+	copy(urls, c.agents)
+	return
+}
+
+// Start ship parts of stress load to agents, start them together and merge their samples
+func (c *coordinator) Start(
+	ctx context.Context,
+	client string,
+	tester models.Tester,
+	params []models.StateSelector,
+	opts *models.Options,
+) (newParams []models.StateSelector, err error) {
+	agents := c.Agents()
+	if len(agents) == 0 {
+		return nil, common.ErrInvalidConfig("no agents registered")
+	}
+	parts := splitLoad(*opts.Conf.StressLoad, len(params), len(agents))
+
+	// ship jobs, agents wait for start
+	ids := make([]string, len(parts))
+	var offset int
+	for i, part := range parts {
+		scenario := *opts.Conf
+		stressLoad := part.load
+		scenario.StressLoad = &stressLoad
+		job := Job{
+			LaunchID: opts.LaunchID,
+			Client:   client,
+			Tester:   tester.MethodName(),
+			Scenario: scenario,
+			State:    c.state.Dump(params[offset : offset+part.shots]),
+		}
+		offset += part.shots
+		if ids[i], err = c.prepare(ctx, agents[i], &job); err != nil {
+			// jobs of other agents won't be started
+			for j := 0; j < i; j++ {
+				c.release(agents[j], ids[j])
+			}
+			return nil, errors.Wrapf(err, "prepare agent %s", agents[i])
+		}
+	}
+
+	// start all agents together and merge samples
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	merger := newSampleMerger(c.metrics)
+	cancelReport := c.startReport(merger, opts)
+	errs := make(chan error, len(ids))
+	for i := range ids {
+		go func(agent, id string) {
+			err := c.start(ctx, agent, id, merger)
+			if err != nil {
+				err = errors.Wrapf(err, "agent %s", agent)
+				cancel()
+			}
+			errs <- err
+		}(agents[i], ids[i])
+	}
+	for range ids {
+		if e := <-errs; e != nil && err == nil {
+			err = e
+		}
+	}
+	close(cancelReport)
+	c.reportStop(merger, opts, err)
+	if err != nil {
+		c.logger.WithError(err).Warn("distributed stress load failed")
+		return nil, err
+	}
+
+	// check service level objectives of whole stress load
+	if err = opts.Conf.StressLoad.CheckThresholds(merger.summary()); err != nil {
+		c.logger.WithError(err).Warn("stress load thresholds breached")
+		return nil, err
+	}
+	return params, nil
+}
+
+func (c *coordinator) prepare(ctx context.Context, agent string, job *Job) (id string, err error) {
+	data, err := json.Marshal(job)
+	if err != nil {
+		return
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, agent+preparePath, bytes.NewReader(data))
+	if err != nil {
+		return
+	}
+	req.Header.Set(authHeader, bearer+c.token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var res prepared
+	err = json.NewDecoder(resp.Body).Decode(&res)
+	return res.ID, err
+}
+
+// release forget prepared job of agent, failure is only logged, because agent has nothing to run
+func (c *coordinator) release(agent, id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+	logger := c.logger.WithField("agent", agent).WithField("job", id)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, agent+releasePath+"?"+jobParam+"="+id, nil)
+	if err != nil {
+		logger.WithError(err).Warn("failed release job of agent")
+		return
+	}
+	req.Header.Set(authHeader, bearer+c.token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		logger.WithError(err).Warn("failed release job of agent")
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		logger.WithField("status", resp.StatusCode).Warn("failed release job of agent")
+	}
+}
+
+func (c *coordinator) start(ctx context.Context, agent, id string, merger *sampleMerger) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, agent+startPath+"?"+jobParam+"="+id, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set(authHeader, bearer+c.token)
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var msg Message
+		if err = json.Unmarshal(scanner.Bytes(), &msg); err != nil {
+			return err
+		}
+		if msg.Sample != nil {
+			merger.add(msg.Sample)
+		}
+		if msg.Done {
+			if msg.Error != "" {
+				return errors.New(msg.Error)
+			}
+			return nil
+		}
+	}
+	if err = scanner.Err(); err != nil {
+		return err
+	}
+	return io.ErrUnexpectedEOF
+}
+
+// sampleMerger merge samples streams of agents into meter of coordinator, it keeps stats of whole load
+// for thresholds, stats of window for checkpoints of soak load and count of samples for live progress
+type sampleMerger struct {
+	mu        sync.Mutex
+	meter     common.Meter
+	started   time.Time
+	latencies *common.Histogram
+	errors    int
+	responses int
+
+	windowStarted   time.Time
+	windowLatencies *common.Histogram
+	windowErrors    int
+}
+
+func (m *sampleMerger) add(data *common.RequestData) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.latencies.Add(data.Latency)
+	m.windowLatencies.Add(data.Latency)
+	m.responses++
+	if data.Code != http.StatusOK {
+		m.errors++
+		m.windowErrors++
+	}
+	m.meter.AddRequest(data)
+}
+
+func (m *sampleMerger) summary() *common.Summary {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	latencies := *m.latencies
+	return common.NewSummary(&latencies, m.errors, time.Since(m.started))
+}
+
+// counts return count of samples since previous call and count of errors of whole load
+func (m *sampleMerger) counts() (responses, errors int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	responses, m.responses = m.responses, 0
+	return responses, m.errors
+}
+
+// checkpoint return summary of samples merged since previous checkpoint and start new window
+func (m *sampleMerger) checkpoint() *common.Summary {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	summary := common.NewSummary(m.windowLatencies, m.windowErrors, now.Sub(m.windowStarted))
+	m.windowStarted = now
+	m.windowLatencies = common.NewHistogram()
+	m.windowErrors = 0
+	return summary
+}
+
+func newSampleMerger(meter common.Meter) *sampleMerger {
+	now := time.Now()
+	return &sampleMerger{
+		meter:           meter,
+		started:         now,
+		latencies:       common.NewHistogram(),
+		windowStarted:   now,
+		windowLatencies: common.NewHistogram(),
+	}
+}
+
+// NewCoordinator construct coordinator of agents, state must implement models.StateTransfer,
+// token is shared secret of coordinator and agents
+func NewCoordinator(
+	agents []string, token string, state models.State, client *http.Client, logger log.Logger,
+) (Coordinator, error) {
+	transfer, ok := state.(models.StateTransfer)
+	if !ok {
+		return nil, common.ErrInvalidConfig("state doesn't implement state transfer")
+	}
+	if token == "" {
+		return nil, common.ErrInvalidConfig("coordinator of agents without token")
+	}
+	if client == nil {
+		client = http.DefaultClient
+	}
+	c := &coordinator{
+		token:       token,
+		state:       transfer,
+		client:      client,
+		logger:      logger,
+		progress:    common.NewBroadcaster[models.StressProgress](),
+		checkpoints: common.NewBroadcaster[models.Checkpoint](),
+	}
+	for _, agent := range agents {
+		c.AddAgent(agent)
+	}
+	return c, nil
+}
@@ -0,0 +1,266 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/log"
+	"github.com/lueurxax/e2e/pkg/models"
+	"github.com/lueurxax/e2e/pkg/testerspool"
+)
+
+const testToken = "secret"
+
+type countingTester struct {
+	runs int64
+}
+
+func (t *countingTester) MethodName() string {
+	return "Count"
+}
+
+func (t *countingTester) RequiredFields() []string {
+	return nil
+}
+
+func (t *countingTester) ReturnedFields() []string {
+	return nil
+}
+
+func (t *countingTester) Run(context.Context, string, models.StateSelector, *models.Options) (models.StateSelector, error) {
+	atomic.AddInt64(&t.runs, 1)
+	return nil, nil
+}
+
+type countingMeter struct {
+	requests int64
+}
+
+func (m *countingMeter) NewLaunch(string) {}
+
+func (m *countingMeter) NewStress(string, common.StressLoad) {}
+
+func (m *countingMeter) AddRequest(*common.RequestData) {
+	atomic.AddInt64(&m.requests, 1)
+}
+
+func (m *countingMeter) Reset() {}
+
+func newTestLogger() log.Logger {
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+	return log.NewLogger(logger)
+}
+
+// startAgent run agent on localhost with own tester
+func startAgent(t *testing.T, token string) (*countingTester, string) {
+	t.Helper()
+	tester := &countingTester{}
+	state := NewRawState()
+	handler, err := NewServer(
+		testerspool.NewTestersPool([]models.Tester{tester}),
+		func() models.State { return state },
+		token,
+		newTestLogger(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	return tester, srv.URL
+}
+
+// failingAgent accept job and fail it after one sample
+func failingAgent(t *testing.T) string {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc(preparePath, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(prepared{ID: "job"})
+	})
+	mux.HandleFunc(startPath, func(w http.ResponseWriter, r *http.Request) {
+		encoder := json.NewEncoder(w)
+		_ = encoder.Encode(Message{Sample: &common.RequestData{Code: http.StatusOK, Latency: time.Millisecond}})
+		_ = encoder.Encode(Message{Done: true, Error: "engine failed"})
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv.URL
+}
+
+func newTestLoad(t *testing.T, state models.State) ([]models.StateSelector, *models.Options) {
+	t.Helper()
+	conf := &models.Test{
+		Name: "distributed",
+		StressLoad: &common.StressLoad{
+			Instances: 2,
+			Duration:  common.Duration(time.Second),
+			From:      10,
+			To:        10,
+		},
+		Params: map[string]interface{}{"bucket": "test"},
+	}
+	conf.Prepare()
+	selectors := state.AddToState(state.Reset(conf.StressLoad.ShootCount), conf.InitState.GlobalParams)
+	return selectors, &models.Options{Conf: conf, LaunchID: "launch"}
+}
+
+func newTestCoordinator(t *testing.T, agents []string, state models.State, meter common.Meter) Coordinator {
+	t.Helper()
+	c, err := NewCoordinator(agents, testToken, state, nil, newTestLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.Register(meter)
+	return c
+}
+
+func TestCoordinatorMergeSamplesOfAgents(t *testing.T) {
+	first, firstURL := startAgent(t, testToken)
+	second, secondURL := startAgent(t, testToken)
+	state := NewRawState()
+	meter := &countingMeter{}
+	c := newTestCoordinator(t, []string{firstURL, secondURL}, state, meter)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	progress := make(chan *models.StressProgress, 16)
+	c.SubscribeOnProgress(ctx, progress)
+
+	selectors, opts := newTestLoad(t, state)
+	if _, err := c.Start(ctx, "client", first, selectors, opts); err != nil {
+		t.Fatal(err)
+	}
+	if first.runs == 0 || second.runs == 0 {
+		t.Fatalf("load isn't split across agents: %d and %d runs", first.runs, second.runs)
+	}
+	if merged := atomic.LoadInt64(&meter.requests); merged != first.runs+second.runs {
+		t.Fatalf("merged %d samples of %d runs", merged, first.runs+second.runs)
+	}
+	if last := lastProgress(progress); last == nil || last.StopReason != scheduleFinished {
+		t.Fatalf("unexpected last progress %+v", last)
+	}
+	if opts.StopReason != scheduleFinished {
+		t.Fatalf("unexpected stop reason of run %q", opts.StopReason)
+	}
+}
+
+func TestCoordinatorFailedAgent(t *testing.T) {
+	tester, agentURL := startAgent(t, testToken)
+	failedURL := failingAgent(t)
+	state := NewRawState()
+	c := newTestCoordinator(t, []string{agentURL, failedURL}, state, &countingMeter{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	progress := make(chan *models.StressProgress, 16)
+	c.SubscribeOnProgress(ctx, progress)
+
+	selectors, opts := newTestLoad(t, state)
+	_, err := c.Start(ctx, "client", tester, selectors, opts)
+	if err == nil || !strings.Contains(err.Error(), failedURL) || !strings.Contains(err.Error(), "engine failed") {
+		t.Fatalf("expected error of failed agent, got %v", err)
+	}
+	if last := lastProgress(progress); last == nil || !strings.Contains(last.StopReason, "engine failed") {
+		t.Fatalf("unexpected last progress %+v", last)
+	}
+}
+
+func TestAgentRejectJobWithoutToken(t *testing.T) {
+	tester, agentURL := startAgent(t, "other")
+	state := NewRawState()
+	c := newTestCoordinator(t, []string{agentURL}, state, &countingMeter{})
+
+	selectors, opts := newTestLoad(t, state)
+	_, err := c.Start(context.Background(), "client", tester, selectors, opts)
+	if err == nil || !strings.Contains(err.Error(), "status 401") {
+		t.Fatalf("expected unauthorized error, got %v", err)
+	}
+	if tester.runs != 0 {
+		t.Fatalf("unauthorized job made %d runs", tester.runs)
+	}
+}
+
+func TestCoordinatorReleasePreparedJobs(t *testing.T) {
+	var released int64
+	mux := http.NewServeMux()
+	mux.HandleFunc(preparePath, func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(prepared{ID: "job"})
+	})
+	mux.HandleFunc(releasePath, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get(jobParam) == "job" {
+			atomic.AddInt64(&released, 1)
+		}
+	})
+	preparedAgent := httptest.NewServer(mux)
+	t.Cleanup(preparedAgent.Close)
+	tester, rejectingURL := startAgent(t, "other")
+	state := NewRawState()
+	c := newTestCoordinator(t, []string{preparedAgent.URL, rejectingURL}, state, &countingMeter{})
+
+	selectors, opts := newTestLoad(t, state)
+	if _, err := c.Start(context.Background(), "client", tester, selectors, opts); err == nil {
+		t.Fatal("expected error of rejected prepare")
+	}
+	if released != 1 {
+		t.Fatalf("prepared job released %d times", released)
+	}
+}
+
+func TestAgentReleaseJob(t *testing.T) {
+	_, agentURL := startAgent(t, testToken)
+	state := NewRawState()
+	selectors, opts := newTestLoad(t, state)
+	c := newTestCoordinator(t, []string{agentURL}, state, &countingMeter{}).(*coordinator)
+	id, err := c.prepare(context.Background(), agentURL, &Job{
+		Tester:   "Count",
+		Scenario: *opts.Conf,
+		State:    c.state.Dump(selectors),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.release(agentURL, id)
+	err = c.start(context.Background(), agentURL, id, newSampleMerger(&countingMeter{}))
+	if err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Fatalf("expected unknown released job, got %v", err)
+	}
+}
+
+func TestRawStateTransfer(t *testing.T) {
+	state := NewRawState()
+	transfer := state.(models.StateTransfer)
+	raw := state.(models.RawState)
+	selectors := state.AddToState(state.Reset(2), map[string]interface{}{"bucket": "test"})
+	loaded := transfer.Load(transfer.Dump(selectors))
+	result, err := raw.NewRaw(loaded[1], map[string]interface{}{"key": "value"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params := raw.Raw(result); params["bucket"] != "test" || params["key"] != "value" {
+		t.Fatalf("unexpected params of result %v", params)
+	}
+	if params := raw.Raw(loaded[1]); len(params) != 1 {
+		t.Fatalf("result changed params of shot %v", params)
+	}
+}
+
+func lastProgress(ch <-chan *models.StressProgress) (last *models.StressProgress) {
+	for {
+		select {
+		case progress := <-ch:
+			last = progress
+		default:
+			return
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package agent
+
+import (
+	"bytes"
+	"encoding/json"
+
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/models"
+)
+
+const (
+	preparePath = "/prepare"
+	startPath   = "/start"
+	releasePath = "/release"
+	healthPath  = "/health"
+	jobParam    = "job"
+
+	// authHeader header with shared token of coordinator and agents
+	authHeader = "Authorization"
+	bearer     = "Bearer "
+)
+
+// Job part of stress load shipped by coordinator to agent
+type Job struct {
+	LaunchID string                   `json:"launchId"`
+	Client   string                   `json:"client"`
+	Tester   string                   `json:"tester"`
+	Scenario models.Test              `json:"scenario"`
+	State    []map[string]interface{} `json:"state"`
+}
+
+// Message line of agent stream, contains sample or final result of job
+type Message struct {
+	Sample *common.RequestData `json:"sample,omitempty"`
+	Done   bool                `json:"done,omitempty"`
+	Error  string              `json:"error,omitempty"`
+}
+
+type prepared struct {
+	ID string `json:"id"`
+}
+
+// decodeJob decode job, numbers of state are restored as int if it is possible,
+// because generated state expects the same types as in yaml config
+func decodeJob(data []byte) (job *Job, err error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	job = &Job{}
+	if err = decoder.Decode(job); err != nil {
+		return nil, err
+	}
+	for _, params := range job.State {
+		common.NormalizeNumbers(params)
+	}
+	common.NormalizeNumbers(job.Scenario.Params)
+	return
+}
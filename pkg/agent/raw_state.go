@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/models"
+)
+
+// rawSelector selector of raw state, it keeps params of shot itself,
+// so states of finished jobs are released together with their selectors
+type rawSelector struct {
+	params map[string]interface{}
+}
+
+func (s *rawSelector) Index() int {
+	return -1
+}
+
+// rawState state of agent without generated state, it serves declarative, script and plugin testers.
+// Values of shipped states keep types decoded from job, they aren't converted to types of fields.
+type rawState struct{}
+
+// NewRawState construct state of agent for testers without generated methods,
+// it implements models.StateTransfer and models.RawState
+func NewRawState() models.State {
+	return &rawState{}
+}
+
+func (s *rawState) Reset(count int) []models.StateSelector {
+	selectors := make([]models.StateSelector, count)
+	for i := range selectors {
+		selectors[i] = &rawSelector{params: map[string]interface{}{}}
+	}
+	return selectors
+}
+
+// Prepare do nothing, coordinator ships prepared states
+func (s *rawState) Prepare(common.InitState) {}
+
+// MergeToState do nothing, agent doesn't return states of shots to coordinator
+func (s *rawState) MergeToState([]models.StateSelector) {}
+
+// MergeToStateRepeat do nothing, agent doesn't return states of shots to coordinator
+func (s *rawState) MergeToStateRepeat(models.StateSelector) {}
+
+func (s *rawState) AddToState(
+	selectors []models.StateSelector, params ...map[string]interface{},
+) []models.StateSelector {
+	res := make([]models.StateSelector, len(selectors))
+	for i, selector := range selectors {
+		res[i] = merged(s.Raw(selector), params...)
+	}
+	return res
+}
+
+func (s *rawState) Dump(selectors []models.StateSelector) []map[string]interface{} {
+	params := make([]map[string]interface{}, len(selectors))
+	for i, selector := range selectors {
+		params[i] = s.Raw(selector)
+	}
+	return params
+}
+
+func (s *rawState) Load(params []map[string]interface{}) []models.StateSelector {
+	selectors := make([]models.StateSelector, len(params))
+	for i, param := range params {
+		selectors[i] = merged(param)
+	}
+	return selectors
+}
+
+func (s *rawState) Raw(selector models.StateSelector) map[string]interface{} {
+	raw, ok := selector.(*rawSelector)
+	if !ok {
+		return map[string]interface{}{}
+	}
+	return raw.params
+}
+
+func (s *rawState) NewRaw(selector models.StateSelector, values map[string]interface{}) (models.StateSelector, error) {
+	return merged(s.Raw(selector), values), nil
+}
+
+// merged selector of copy of params overridden by values
+func merged(params map[string]interface{}, values ...map[string]interface{}) *rawSelector {
+	res := make(map[string]interface{}, len(params))
+	for key, value := range params {
+		res[key] = value
+	}
+	for _, value := range values {
+		for key, v := range value {
+			res[key] = v
+		}
+	}
+	return &rawSelector{params: res}
+}
@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"time"
+
+	"github.com/lueurxax/e2e/pkg/models"
+)
+
+// scheduleFinished stop reason of distributed load, which all agents finished without error
+const scheduleFinished = "schedule finished"
+
+// startReport publish live progress every second and checkpoints of soak load, computed from merged samples
+func (c *coordinator) startReport(merger *sampleMerger, opts *models.Options) (cancel chan struct{}) {
+	cancel = make(chan struct{})
+	go func(cancel chan struct{}) {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		var checkpoints <-chan time.Time
+		if opts.Conf.StressLoad.IsSoak() {
+			checkpointTicker := time.NewTicker(opts.Conf.StressLoad.Soak.GetCheckpoint())
+			defer checkpointTicker.Stop()
+			checkpoints = checkpointTicker.C
+		}
+		for {
+			select {
+			case <-ticker.C:
+				responses, errors := merger.counts()
+				// agents stream only finished requests, so active users and requests are unknown
+				c.progress.Publish(&models.StressProgress{
+					LaunchID:           opts.LaunchID,
+					Scenario:           opts.Conf.Name,
+					ResponsesPerSecond: responses,
+					RequestsPerSecond:  responses,
+					Errors:             errors,
+				})
+			case <-checkpoints:
+				c.checkpoint(merger, opts)
+			case <-cancel:
+				return
+			}
+		}
+	}(cancel)
+	return
+}
+
+// reportStop publish the last checkpoint of soak load and why shooting stopped
+func (c *coordinator) reportStop(merger *sampleMerger, opts *models.Options, err error) {
+	if opts.Conf.StressLoad.IsSoak() {
+		c.checkpoint(merger, opts)
+	}
+	reason := scheduleFinished
+	if err != nil {
+		reason = err.Error()
+	}
+	_, errors := merger.counts()
+	c.logger.WithField("scenario", opts.Conf.Name).WithField("id", opts.LaunchID).
+		WithField("reason", reason).Info("shooting of agents stopped")
+	opts.StopReason = reason
+	c.progress.Publish(&models.StressProgress{
+		LaunchID:   opts.LaunchID,
+		Scenario:   opts.Conf.Name,
+		Errors:     errors,
+		StopReason: reason,
+	})
+}
+
+// checkpoint summarize samples merged since previous checkpoint, memory of remote agents is unknown
+func (c *coordinator) checkpoint(merger *sampleMerger, opts *models.Options) {
+	summary := merger.checkpoint()
+	checkpoint := &models.Checkpoint{
+		LaunchID:  opts.LaunchID,
+		Scenario:  opts.Conf.Name,
+		Time:      time.Now().Format(time.RFC3339),
+		Elapsed:   time.Since(merger.started).Round(time.Second).String(),
+		Requests:  summary.Requests,
+		Errors:    summary.Errors,
+		ErrorRate: summary.ErrorRate(),
+		Rps:       summary.RPS(),
+		P50:       milliseconds(summary.Percentile(50)),
+		P90:       milliseconds(summary.Percentile(90)),
+		P99:       milliseconds(summary.Percentile(99)),
+		Max:       milliseconds(summary.Max()),
+	}
+	c.logger.WithField("scenario", checkpoint.Scenario).WithField("id", checkpoint.LaunchID).
+		WithField("elapsed", checkpoint.Elapsed).
+		WithField("requests", checkpoint.Requests).
+		WithField("error_rate", checkpoint.ErrorRate).
+		WithField("p99", checkpoint.P99).
+		Info("soak checkpoint of agents")
+	c.checkpoints.Publish(checkpoint)
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
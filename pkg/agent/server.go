@@ -0,0 +1,207 @@
+package agent
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	uuid "github.com/satori/go.uuid"
+
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/log"
+	"github.com/lueurxax/e2e/pkg/models"
+	"github.com/lueurxax/e2e/pkg/pandoraconnector"
+	"github.com/lueurxax/e2e/pkg/testerspool"
+)
+
+// samplesBuffer size of samples queue between engine and response stream
+const samplesBuffer = 1024
+
+// server agent of distributed stress load, runs shipped jobs with local pandora engine
+type server struct {
+	mu       sync.Mutex
+	jobs     map[string]*Job
+	token    string
+	testers  testerspool.TestersPool
+	newState func() models.State
+	logger   log.Logger
+}
+
+// NewServer construct agent http handler, newState return state for every job, testers must be bound
+// to returned state, state must implement models.StateTransfer. Jobs are accepted only from coordinator
+// with the same token.
+func NewServer(
+	testers testerspool.TestersPool, newState func() models.State, token string, logger log.Logger,
+) (http.Handler, error) {
+	if token == "" {
+		return nil, common.ErrInvalidConfig("agent without token")
+	}
+	s := &server{
+		jobs:     map[string]*Job{},
+		token:    token,
+		testers:  testers,
+		newState: newState,
+		logger:   logger,
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(preparePath, s.authorized(s.prepare))
+	mux.HandleFunc(startPath, s.authorized(s.start))
+	mux.HandleFunc(releasePath, s.authorized(s.release))
+	mux.HandleFunc(healthPath, func(w http.ResponseWriter, r *http.Request) {})
+	return mux, nil
+}
+
+// authorized reject requests without shared token of coordinator
+func (s *server) authorized(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.Header.Get(authHeader), bearer)
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			s.logger.WithField("remote", r.RemoteAddr).Warn("unauthorized request of agent")
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// prepare store job until coordinator start all agents
+func (s *server) prepare(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	data, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	job, err := decodeJob(data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if job.Scenario.StressLoad == nil {
+		http.Error(w, common.ErrInvalidConfig("job without stress load").Error(), http.StatusBadRequest)
+		return
+	}
+	if _, err = s.testers.Get(job.Tester); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	id := uuid.NewV4().String()
+	s.mu.Lock()
+	s.jobs[id] = job
+	s.mu.Unlock()
+	_ = json.NewEncoder(w).Encode(prepared{ID: id})
+}
+
+// release forget prepared job, which won't be started by coordinator
+func (s *server) release(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	id := r.URL.Query().Get(jobParam)
+	s.mu.Lock()
+	_, ok := s.jobs[id]
+	delete(s.jobs, id)
+	s.mu.Unlock()
+	if !ok {
+		http.Error(w, "unknown job "+id, http.StatusNotFound)
+		return
+	}
+	s.logger.WithField("job", id).Info("release job")
+}
+
+// start run prepared job and stream samples until engine finish
+func (s *server) start(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	id := r.URL.Query().Get(jobParam)
+	s.mu.Lock()
+	job, ok := s.jobs[id]
+	delete(s.jobs, id)
+	s.mu.Unlock()
+	if !ok {
+		http.Error(w, "unknown job "+id, http.StatusNotFound)
+		return
+	}
+	logger := s.logger.WithField("job", id).WithField("id", job.LaunchID)
+	logger.Info("start job")
+
+	meter := newStreamMeter()
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	result := make(chan error, 1)
+	go func() {
+		defer close(meter.samples)
+		result <- s.run(ctx, job, meter, logger)
+	}()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	encoder := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	for sample := range meter.samples {
+		if err := encoder.Encode(Message{Sample: sample}); err != nil {
+			logger.WithError(err).Warn("coordinator disconnected")
+			cancel()
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	done := Message{Done: true}
+	if err := <-result; err != nil {
+		done.Error = err.Error()
+	}
+	_ = encoder.Encode(done)
+	logger.Info("job finished")
+}
+
+func (s *server) run(ctx context.Context, job *Job, meter common.Meter, logger log.Logger) error {
+	tester, err := s.testers.Get(job.Tester)
+	if err != nil {
+		return err
+	}
+	state, ok := s.newState().(models.StateTransfer)
+	if !ok {
+		return common.ErrInvalidConfig("state of agent doesn't implement state transfer")
+	}
+	selectors := state.Load(job.State)
+	conf := job.Scenario
+	conf.Prepare()
+
+	connector := pandoraconnector.NewConnector(logger)
+	connector.Register(meter)
+	_, err = connector.Start(ctx, job.Client, tester, selectors, &models.Options{
+		Conf:     &conf,
+		LaunchID: job.LaunchID,
+	})
+	return err
+}
+
+// streamMeter send samples of agent to coordinator
+type streamMeter struct {
+	samples chan *common.RequestData
+}
+
+func (m *streamMeter) NewLaunch(string) {}
+
+func (m *streamMeter) NewStress(string, common.StressLoad) {}
+
+func (m *streamMeter) AddRequest(data *common.RequestData) {
+	sample := *data
+	m.samples <- &sample
+}
+
+func (m *streamMeter) Reset() {}
+
+func newStreamMeter() *streamMeter {
+	return &streamMeter{samples: make(chan *common.RequestData, samplesBuffer)}
+}
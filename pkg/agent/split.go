@@ -0,0 +1,61 @@
+package agent
+
+import "github.com/lueurxax/e2e/common"
+
+// part of stress load for one agent
+type part struct {
+	load  common.StressLoad
+	shots int
+}
+
+// splitLoad split instances, rps schedule and ammo across agents, thresholds are checked by coordinator
+// on merged samples, so they are removed from parts.
+// Open model is split by rps, closed model is split by virtual users.
+func splitLoad(load common.StressLoad, shots, agents int) []part {
+	weight := load.From + load.To
+	if load.IsClosed() {
+		weight = load.Instances
+	}
+	if weight == 0 {
+		return []part{{load: load, shots: shots}}
+	}
+	parts := make([]part, 0, agents)
+	from := splitInt(load.From, agents)
+	to := splitInt(load.To, agents)
+	instances := splitInt(load.Instances, agents)
+	var shipped int
+	for i := 0; i < agents; i++ {
+		share := from[i] + to[i]
+		if load.IsClosed() {
+			share = instances[i]
+		}
+		if share == 0 {
+			continue
+		}
+		p := part{load: load}
+		p.load.From, p.load.To, p.load.Thresholds = from[i], to[i], nil
+		p.load.Instances = instances[i]
+		if p.load.Instances == 0 {
+			p.load.Instances = 1
+		}
+		p.shots = shots * share / weight
+		shipped += p.shots
+		parts = append(parts, p)
+	}
+	// rest of ammo after integer division goes to first agent
+	if len(parts) > 0 {
+		parts[0].shots += shots - shipped
+	}
+	return parts
+}
+
+func splitInt(value, parts int) []int {
+	res := make([]int, parts)
+	for i := range res {
+		res[i] = value / parts
+		if i < value%parts {
+			res[i]++
+		}
+	}
+	return res
+}
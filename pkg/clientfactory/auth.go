@@ -0,0 +1,254 @@
+package clientfactory
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/declarative"
+	"github.com/lueurxax/e2e/pkg/secrets"
+)
+
+// DefaultAuthTimeout timeout of token request of client without timeout
+const DefaultAuthTimeout = 30 * time.Second
+
+// defaultLoginBody body of login request
+const defaultLoginBody = `{"email":{{json .email}},"password":{{json .password}}}`
+
+// maxAuthErrorBody count of bytes of response body in error of token request
+const maxAuthErrorBody = 256
+
+// Authenticator authorize requests of client by auth strategy of config
+type Authenticator interface {
+	// Credentials return header of request and its value, token is obtained on first call,
+	// then it is cached and refreshed on expiry
+	Credentials(ctx context.Context) (header, value string, err error)
+	// Invalidate drop cached token rejected by server, token obtained after rejection is kept
+	Invalidate(value string)
+}
+
+// staticAuth credentials, which don't expire
+type staticAuth struct {
+	header, value string
+}
+
+func (a *staticAuth) Credentials(context.Context) (string, string, error) {
+	return a.header, a.value, nil
+}
+
+func (a *staticAuth) Invalidate(string) {}
+
+// tokenAuth bearer token of token endpoint, concurrent requests wait for one request of token
+type tokenAuth struct {
+	client     string
+	http       *http.Client
+	newRequest func(ctx context.Context) (*http.Request, error)
+	token      *declarative.Extractor
+	expires    *declarative.Extractor
+	ttl        time.Duration
+
+	mu    sync.Mutex
+	value string
+	// refreshAt zero for token without lifetime
+	refreshAt time.Time
+}
+
+func (a *tokenAuth) Credentials(ctx context.Context) (string, string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.value == "" || !a.refreshAt.IsZero() && !time.Now().Before(a.refreshAt) {
+		if err := a.refresh(ctx); err != nil {
+			return "", "", err
+		}
+	}
+	return "Authorization", a.value, nil
+}
+
+func (a *tokenAuth) Invalidate(value string) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.value == value {
+		a.value = ""
+	}
+}
+
+// refresh request token, it is refreshed after 90% of its lifetime, so requests don't get expired token
+func (a *tokenAuth) refresh(ctx context.Context) error {
+	req, err := a.newRequest(ctx)
+	if err != nil {
+		return err
+	}
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return common.ErrAuthFailed(a.client, err.Error())
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return common.ErrAuthFailed(a.client, err.Error())
+	}
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		if len(data) > maxAuthErrorBody {
+			data = data[:maxAuthErrorBody]
+		}
+		return common.ErrAuthFailed(a.client, fmt.Sprintf("status %d: %s", resp.StatusCode, data))
+	}
+	values, err := a.token.Extract(data)
+	if err != nil {
+		return err
+	}
+	token, ok := values["token"].(string)
+	if !ok || token == "" {
+		return common.ErrAuthFailed(a.client, "response has no token")
+	}
+	lifetime := a.ttl
+	// lifetime of response is optional
+	if values, err = a.expires.Extract(data); err == nil {
+		if seconds, ok := values["expires"].(json.Number); ok {
+			if value, err := seconds.Float64(); err == nil && value > 0 {
+				lifetime = time.Duration(value * float64(time.Second))
+			}
+		}
+	}
+	secrets.Remember(token)
+	a.value = "Bearer " + token
+	a.refreshAt = time.Time{}
+	if lifetime > 0 {
+		a.refreshAt = time.Now().Add(lifetime - lifetime/10)
+	}
+	return nil
+}
+
+// rememberCredentials remember credentials of client as secrets, so plain values of config are redacted too
+func rememberCredentials(conf common.Client) {
+	secrets.Remember(conf.Password)
+	secrets.Remember(conf.Token)
+	if conf.Auth != nil {
+		secrets.Remember(conf.Auth.ClientSecret)
+		secrets.Remember(conf.Auth.Key)
+	}
+}
+
+// newAuthenticator build authenticator of auth strategy of client, client without auth has no authenticator
+func newAuthenticator(conf common.Client) (Authenticator, error) {
+	rememberCredentials(conf)
+	auth := conf.Auth
+	if auth == nil {
+		return nil, nil
+	}
+	if err := auth.Validate(&conf); err != nil {
+		return nil, err
+	}
+	switch auth.Type {
+	case common.AuthBasic:
+		credentials := base64.StdEncoding.EncodeToString([]byte(conf.Email + ":" + conf.Password))
+		secrets.Remember(credentials)
+		return &staticAuth{header: "Authorization", value: "Basic " + credentials}, nil
+	case common.AuthAPIKey:
+		return &staticAuth{header: auth.GetHeader(), value: auth.Key}, nil
+	}
+	return newTokenAuth(conf)
+}
+
+func newTokenAuth(conf common.Client) (*tokenAuth, error) {
+	auth := conf.Auth
+	name := "auth of client " + conf.Name
+	endpoint, err := authURL(conf)
+	if err != nil {
+		return nil, common.ErrInvalidConfig(fmt.Sprintf("%s: %s", name, err))
+	}
+	transport, err := newTransport(conf)
+	if err != nil {
+		return nil, err
+	}
+	timeout := conf.Timeout.Duration()
+	if timeout <= 0 {
+		timeout = DefaultAuthTimeout
+	}
+	a := &tokenAuth{
+		client: conf.Name,
+		http:   &http.Client{Transport: transport, Timeout: timeout},
+		ttl:    auth.TTL.Duration(),
+	}
+	if a.token, err = declarative.NewExtractor(name, map[string]string{"token": auth.GetTokenPath()}); err != nil {
+		return nil, err
+	}
+	if a.expires, err = declarative.NewExtractor(name, map[string]string{"expires": auth.GetExpiresPath()}); err != nil {
+		return nil, err
+	}
+	if auth.Type == common.AuthOAuth2 {
+		a.newRequest = oauth2Request(endpoint, auth)
+		return a, nil
+	}
+	body := auth.Body
+	if body == "" {
+		body = defaultLoginBody
+	}
+	tmpl, err := declarative.ParseTemplate(name+".body", body)
+	if err != nil {
+		return nil, err
+	}
+	a.newRequest = loginRequest(endpoint, tmpl, map[string]interface{}{"email": conf.Email, "password": conf.Password})
+	return a, nil
+}
+
+// authURL resolve url of auth against url of client, token endpoint must be http endpoint
+func authURL(conf common.Client) (string, error) {
+	endpoint := conf.Auth.URL
+	if !strings.Contains(endpoint, "://") {
+		endpoint = strings.TrimSuffix(conf.Url, "/") + "/" + strings.TrimPrefix(endpoint, "/")
+	}
+	parsed, err := url.Parse(endpoint)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return "", fmt.Errorf("url %s isn't http url", endpoint)
+	}
+	return endpoint, nil
+}
+
+func loginRequest(
+	endpoint string,
+	body *template.Template,
+	params map[string]interface{},
+) func(ctx context.Context) (*http.Request, error) {
+	return func(ctx context.Context) (*http.Request, error) {
+		data, err := declarative.Render(body, params)
+		if err != nil {
+			return nil, err
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	}
+}
+
+// oauth2Request request of client credentials grant, client is authenticated by basic authorization
+func oauth2Request(endpoint string, auth *common.ClientAuth) func(ctx context.Context) (*http.Request, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	if len(auth.Scopes) > 0 {
+		form.Set("scope", strings.Join(auth.Scopes, " "))
+	}
+	return func(ctx context.Context) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth(url.QueryEscape(auth.ClientID), url.QueryEscape(auth.ClientSecret))
+		return req, nil
+	}
+}
@@ -0,0 +1,169 @@
+package clientfactory
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/clientfactory/authfake"
+	"github.com/lueurxax/e2e/pkg/secrets"
+)
+
+const (
+	testEmail    = "user@example.com"
+	testPassword = "password"
+	testClientID = "client"
+	// secret with reserved characters of form encoding
+	testClientSecret = "se:cr%et"
+)
+
+// newAuthServer start token endpoints and api, which requires issued token
+func newAuthServer(t *testing.T, ttl time.Duration) (*authfake.Server, string) {
+	t.Helper()
+	fake := authfake.New(ttl)
+	fake.AddUser(testEmail, testPassword)
+	fake.AddClient(testClientID, testClientSecret)
+	mux := http.NewServeMux()
+	mux.Handle(authfake.LoginPath, fake)
+	mux.Handle(authfake.TokenPath, fake)
+	mux.Handle("/api/", fake.Middleware(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return fake, srv.URL
+}
+
+func newAuthClients(t *testing.T, url string) map[string]*HTTPClient {
+	t.Helper()
+	set, err := Build([]common.Client{
+		{
+			Name:     common.AuthLogin,
+			Url:      url,
+			Email:    testEmail,
+			Password: testPassword,
+			Auth:     &common.ClientAuth{Type: common.AuthLogin, URL: authfake.LoginPath},
+		},
+		{
+			Name: common.AuthOAuth2,
+			Url:  url,
+			Auth: &common.ClientAuth{
+				Type:         common.AuthOAuth2,
+				URL:          authfake.TokenPath,
+				ClientID:     testClientID,
+				ClientSecret: testClientSecret,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = set.Close() })
+	return All[*HTTPClient](set)
+}
+
+func post(t *testing.T, client *HTTPClient) {
+	t.Helper()
+	resp, err := client.Post(client.Resolve("/api/items"), "application/json", strings.NewReader("{}"))
+	if err != nil {
+		t.Error(err)
+		return
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("unexpected status %d", resp.StatusCode)
+	}
+}
+
+func TestTokenAuthCache(t *testing.T) {
+	for _, name := range []string{common.AuthLogin, common.AuthOAuth2} {
+		t.Run(name, func(t *testing.T) {
+			fake, url := newAuthServer(t, time.Minute)
+			client := newAuthClients(t, url)[name]
+			// concurrent requests wait for one request of token
+			var wg sync.WaitGroup
+			for i := 0; i < 20; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					post(t, client)
+				}()
+			}
+			wg.Wait()
+			if issued := fake.Issued(); issued != 1 {
+				t.Fatalf("issued %d tokens", issued)
+			}
+		})
+	}
+}
+
+func TestTokenAuthRefresh(t *testing.T) {
+	const ttl = 300 * time.Millisecond
+	for _, name := range []string{common.AuthLogin, common.AuthOAuth2} {
+		t.Run(name, func(t *testing.T) {
+			fake, url := newAuthServer(t, ttl)
+			client := newAuthClients(t, url)[name]
+			post(t, client)
+
+			// token is refreshed before expiry
+			time.Sleep(ttl)
+			post(t, client)
+			if issued := fake.Issued(); issued != 2 {
+				t.Fatalf("issued %d tokens after expiry", issued)
+			}
+
+			// token rejected by server is refreshed once and request is repeated
+			fake.Revoke()
+			post(t, client)
+			if issued := fake.Issued(); issued != 3 {
+				t.Fatalf("issued %d tokens after revoke", issued)
+			}
+		})
+	}
+}
+
+func TestCredentialsRedacted(t *testing.T) {
+	_, url := newAuthServer(t, time.Minute)
+	set, err := Build([]common.Client{
+		{
+			Name:     common.AuthLogin,
+			Url:      url,
+			Email:    testEmail,
+			Password: testPassword,
+			Auth:     &common.ClientAuth{Type: common.AuthLogin, URL: authfake.LoginPath},
+		},
+		{
+			Name:     common.AuthBasic,
+			Url:      url,
+			Email:    testEmail,
+			Password: testPassword,
+			Auth:     &common.ClientAuth{Type: common.AuthBasic},
+		},
+		{Name: "static", Url: url, Token: "static-token"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = set.Close() }()
+
+	// plain credentials of config are redacted without secret references
+	for _, value := range []string{testPassword, "static-token"} {
+		if redacted := secrets.Redact("value " + value); redacted != "value "+secrets.Mask {
+			t.Fatalf("credential isn't redacted: %s", redacted)
+		}
+	}
+	// issued token and encoded basic credentials are redacted too
+	for _, name := range []string{common.AuthLogin, common.AuthBasic} {
+		_, value, err := set.auths[name].Credentials(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		scheme := strings.Fields(value)[0]
+		if redacted := secrets.Redact(value); redacted != scheme+" "+secrets.Mask {
+			t.Fatalf("credentials of %s aren't redacted: %s", name, redacted)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// Package authfake in-process stand-in of token endpoints for tests of client auth.
+// It issues bearer tokens by login with email and password on POST /login and by oauth2 client credentials grant
+// on POST /token, tokens expire after ttl and are checked by Middleware.
+package authfake
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// paths of token endpoints
+const (
+	LoginPath = "/login"
+	TokenPath = "/token"
+)
+
+// Server stand-in of token endpoints, it is http.Handler, use it with httptest.NewServer
+type Server struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	users   map[string]string
+	clients map[string]string
+	tokens  map[string]time.Time
+	issued  int
+}
+
+// New construct stand-in without users and clients, tokens expire after ttl
+func New(ttl time.Duration) *Server {
+	return &Server{
+		ttl:     ttl,
+		users:   map[string]string{},
+		clients: map[string]string{},
+		tokens:  map[string]time.Time{},
+	}
+}
+
+// AddUser add user allowed to login
+func (s *Server) AddUser(email, password string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[email] = password
+}
+
+// AddClient add oauth2 client allowed to get token by client credentials
+func (s *Server) AddClient(id, secret string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.clients[id] = secret
+}
+
+// Issued count of issued tokens
+func (s *Server) Issued() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.issued
+}
+
+// Revoke issued tokens, so clients have to obtain new ones
+func (s *Server) Revoke() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tokens = map[string]time.Time{}
+}
+
+// Valid return true if value of authorization header has issued token, which isn't expired
+func (s *Server) Valid(authorization string) bool {
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok {
+		return false
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	expires, ok := s.tokens[token]
+	return ok && time.Now().Before(expires)
+}
+
+// Middleware respond unauthorized to requests without valid token
+func (s *Server) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.Valid(r.Header.Get("Authorization")) {
+			writeError(w, http.StatusUnauthorized, "invalid_token")
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ServeHTTP issue token by login or client credentials
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, "invalid_request")
+		return
+	}
+	switch r.URL.Path {
+	case LoginPath:
+		var login struct {
+			Email    string `json:"email"`
+			Password string `json:"password"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&login); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid_request")
+			return
+		}
+		s.issue(w, s.users, login.Email, login.Password)
+	case TokenPath:
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "client_credentials" {
+			writeError(w, http.StatusBadRequest, "unsupported_grant_type")
+			return
+		}
+		id, secret, ok := r.BasicAuth()
+		if !ok {
+			writeError(w, http.StatusUnauthorized, "invalid_client")
+			return
+		}
+		// credentials of basic authorization of oauth2 are form encoded
+		id, _ = url.QueryUnescape(id)
+		secret, _ = url.QueryUnescape(secret)
+		s.issue(w, s.clients, id, secret)
+	default:
+		writeError(w, http.StatusNotFound, "invalid_request")
+	}
+}
+
+func (s *Server) issue(w http.ResponseWriter, accounts map[string]string, name, secret string) {
+	s.mu.Lock()
+	expected, ok := accounts[name]
+	if !ok || expected != secret {
+		s.mu.Unlock()
+		writeError(w, http.StatusUnauthorized, "invalid_client")
+		return
+	}
+	token := uuid.NewV4().String()
+	s.tokens[token] = time.Now().Add(s.ttl)
+	s.issued++
+	s.mu.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"access_token": token,
+		"token_type":   "Bearer",
+		"expires_in":   s.ttl.Seconds(),
+	})
+}
+
+func writeError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": code})
+}
@@ -0,0 +1,123 @@
+package clientfactory
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/lueurxax/e2e/common"
+)
+
+const (
+	plaintextScheme = "grpc://"
+	tlsScheme       = "grpcs://"
+)
+
+// newGRPC connect to url of client: host:port, grpc://host:port for plaintext or grpcs://host:port for tls,
+// tls is used also when tls of client is set. Connection is lazy, so server may be started after config is built.
+// Client is *grpc.ClientConn, headers, token and credentials of auth are sent as metadata
+// and timeout is default deadline of calls. Unary call rejected as unauthenticated is repeated once with new token.
+func newGRPC(conf common.Client, auth Authenticator) (interface{}, error) {
+	target := conf.Url
+	useTLS := conf.TLS != nil
+	switch {
+	case strings.HasPrefix(target, tlsScheme):
+		target = strings.TrimPrefix(target, tlsScheme)
+		useTLS = true
+	case strings.HasPrefix(target, plaintextScheme):
+		target = strings.TrimPrefix(target, plaintextScheme)
+	}
+	creds := insecure.NewCredentials()
+	if useTLS {
+		config, err := newTLSConfig(conf.TLS)
+		if err != nil {
+			return nil, err
+		}
+		creds = credentials.NewTLS(config)
+	}
+	md := metadata.MD{}
+	for key, value := range requestHeaders(conf) {
+		md.Set(key, value)
+	}
+	timeout := conf.Timeout.Duration()
+	return grpc.Dial(target,
+		grpc.WithTransportCredentials(creds),
+		grpc.WithUnaryInterceptor(func(
+			ctx context.Context,
+			method string,
+			req, reply interface{},
+			cc *grpc.ClientConn,
+			invoker grpc.UnaryInvoker,
+			opts ...grpc.CallOption,
+		) error {
+			ctx, cancel := callContext(ctx, md, timeout)
+			defer cancel()
+			authCtx, value, err := authContext(ctx, auth)
+			if err != nil {
+				return err
+			}
+			err = invoker(authCtx, method, req, reply, cc, opts...)
+			if value == "" || status.Code(err) != codes.Unauthenticated {
+				return err
+			}
+			auth.Invalidate(value)
+			if authCtx, _, err = authContext(ctx, auth); err != nil {
+				return err
+			}
+			return invoker(authCtx, method, req, reply, cc, opts...)
+		}),
+		grpc.WithStreamInterceptor(func(
+			ctx context.Context,
+			desc *grpc.StreamDesc,
+			cc *grpc.ClientConn,
+			method string,
+			streamer grpc.Streamer,
+			opts ...grpc.CallOption,
+		) (grpc.ClientStream, error) {
+			// stream outlives interceptor, so it gets only metadata without default deadline
+			ctx, _ = callContext(ctx, md, 0)
+			ctx, _, err := authContext(ctx, auth)
+			if err != nil {
+				return nil, err
+			}
+			return streamer(ctx, desc, cc, method, opts...)
+		}),
+	)
+}
+
+// callContext append metadata of client, which isn't set by call, and set default deadline
+func callContext(ctx context.Context, md metadata.MD, timeout time.Duration) (context.Context, context.CancelFunc) {
+	outgoing, _ := metadata.FromOutgoingContext(ctx)
+	for key, values := range md {
+		if len(outgoing.Get(key)) == 0 {
+			ctx = metadata.AppendToOutgoingContext(ctx, key, values[0])
+		}
+	}
+	if _, ok := ctx.Deadline(); ok || timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// authContext append credentials of auth, which aren't set by call, and return appended value
+func authContext(ctx context.Context, auth Authenticator) (context.Context, string, error) {
+	if auth == nil {
+		return ctx, "", nil
+	}
+	header, value, err := auth.Credentials(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	key := strings.ToLower(header)
+	if outgoing, _ := metadata.FromOutgoingContext(ctx); len(outgoing.Get(key)) > 0 {
+		return ctx, "", nil
+	}
+	return metadata.AppendToOutgoingContext(ctx, key, value), value, nil
+}
@@ -0,0 +1,94 @@
+package clientfactory
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/lueurxax/e2e/common"
+)
+
+// HTTPClient client of http kind, headers, token, auth, tls and timeout of config are applied to every request
+type HTTPClient struct {
+	*http.Client
+	URL string
+}
+
+// Resolve url relative to url of client, absolute url is returned as is
+func (c *HTTPClient) Resolve(path string) string {
+	if strings.Contains(path, "://") {
+		return path
+	}
+	return strings.TrimSuffix(c.URL, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+// headersTransport set headers and credentials of client, headers set by request are kept.
+// Request rejected as unauthorized is repeated once with new token.
+type headersTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+	auth    Authenticator
+}
+
+func (t *headersTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if len(t.headers) == 0 && t.auth == nil {
+		return t.base.RoundTrip(req)
+	}
+	req = req.Clone(req.Context())
+	for key, value := range t.headers {
+		if req.Header.Get(key) == "" {
+			req.Header.Set(key, value)
+		}
+	}
+	if t.auth == nil {
+		return t.base.RoundTrip(req)
+	}
+	header, value, err := t.auth.Credentials(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	if req.Header.Get(header) != "" {
+		return t.base.RoundTrip(req)
+	}
+	req.Header.Set(header, value)
+	resp, err := t.base.RoundTrip(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || req.Body != nil && req.GetBody == nil {
+		return resp, err
+	}
+	t.auth.Invalidate(value)
+	if header, value, err = t.auth.Credentials(req.Context()); err != nil {
+		return resp, nil
+	}
+	if req.GetBody != nil {
+		if req.Body, err = req.GetBody(); err != nil {
+			return resp, nil
+		}
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	req.Header.Set(header, value)
+	return t.base.RoundTrip(req)
+}
+
+func newHTTP(conf common.Client, auth Authenticator) (interface{}, error) {
+	endpoint, err := url.Parse(conf.Url)
+	if err != nil {
+		return nil, err
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" || endpoint.Host == "" {
+		return nil, fmt.Errorf("url %s isn't http url", conf.Url)
+	}
+	transport, err := newTransport(conf)
+	if err != nil {
+		return nil, err
+	}
+	return &HTTPClient{
+		Client: &http.Client{
+			Transport: &headersTransport{base: transport, headers: requestHeaders(conf), auth: auth},
+			Timeout:   conf.Timeout.Duration(),
+		},
+		URL: conf.Url,
+	}, nil
+}
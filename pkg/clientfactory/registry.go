@@ -0,0 +1,163 @@
+// Package clientfactory build ready clients from clients config. Client is built by factory of its kind,
+// http, grpc and s3 kinds are bundled, custom kinds are registered with Register before config is validated.
+package clientfactory
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/lueurxax/e2e/common"
+)
+
+// Factory build client from config, extra fields of config are available for custom kinds,
+// auth is nil for client without auth strategy
+type Factory func(conf common.Client, auth Authenticator) (client interface{}, err error)
+
+var (
+	mu        sync.RWMutex
+	factories = map[string]Factory{
+		common.ClientHTTP: newHTTP,
+		common.ClientGRPC: newGRPC,
+		common.ClientS3:   newS3,
+	}
+)
+
+// Register factory of kind, factory of already registered kind is replaced
+func Register(kind string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	factories[kind] = factory
+}
+
+// Kinds sorted list of registered kinds
+func Kinds() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	kinds := make([]string, 0, len(factories))
+	for kind := range factories {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+func factory(kind string) (Factory, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	f, ok := factories[kind]
+	return f, ok
+}
+
+// Set ready clients by names
+type Set struct {
+	configs []common.Client
+	clients map[string]interface{}
+	auths   map[string]Authenticator
+}
+
+// Get client by name
+func (s *Set) Get(name string) (interface{}, error) {
+	client, ok := s.clients[name]
+	if !ok {
+		return nil, common.ErrUnknownClient(name)
+	}
+	return client, nil
+}
+
+// Auth authenticator of client by name, testers use it for requests made without built client
+func (s *Set) Auth(name string) (Authenticator, error) {
+	if _, ok := s.clients[name]; !ok {
+		return nil, common.ErrUnknownClient(name)
+	}
+	auth, ok := s.auths[name]
+	if !ok {
+		return nil, common.ErrInvalidConfig(fmt.Sprintf("client %s has no auth", name))
+	}
+	return auth, nil
+}
+
+// Configs configs of clients in order of config file
+func (s *Set) Configs() []common.Client {
+	return s.configs
+}
+
+// Close release clients, which hold connections
+func (s *Set) Close() (err error) {
+	for _, client := range s.clients {
+		if closer, ok := client.(io.Closer); ok {
+			if closeErr := closer.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		}
+	}
+	return
+}
+
+// Get client by name with type of client
+func Get[T any](set *Set, name string) (client T, err error) {
+	raw, err := set.Get(name)
+	if err != nil {
+		return client, err
+	}
+	client, ok := raw.(T)
+	if !ok {
+		return client, common.ErrClientHasIncorrectType(name, fmt.Sprintf("%T", client))
+	}
+	return client, nil
+}
+
+// All clients with type of client by names, for example clients of custom kind for wrapper builder of state
+func All[T any](set *Set) map[string]T {
+	result := map[string]T{}
+	for name, raw := range set.clients {
+		if client, ok := raw.(T); ok {
+			result[name] = client
+		}
+	}
+	return result
+}
+
+// Build clients from configs, names must be unique and kinds must be registered,
+// already built clients are released when one of clients cannot be built
+func Build(confs []common.Client) (*Set, error) {
+	set := &Set{
+		configs: confs,
+		clients: make(map[string]interface{}, len(confs)),
+		auths:   map[string]Authenticator{},
+	}
+	for _, conf := range confs {
+		if err := set.build(conf); err != nil {
+			_ = set.Close()
+			return nil, err
+		}
+	}
+	return set, nil
+}
+
+func (s *Set) build(conf common.Client) error {
+	if conf.Name == "" {
+		return common.ErrInvalidConfig("client without name")
+	}
+	if _, ok := s.clients[conf.Name]; ok {
+		return common.ErrInvalidConfig(fmt.Sprintf("duplicated client %s", conf.Name))
+	}
+	f, ok := factory(conf.GetType())
+	if !ok {
+		return common.ErrUnknownClientKind(conf.Name, conf.GetType())
+	}
+	auth, err := newAuthenticator(conf)
+	if err != nil {
+		return err
+	}
+	client, err := f(conf, auth)
+	if err != nil {
+		return common.ErrInvalidConfig(fmt.Sprintf("client %s: %s", conf.Name, err))
+	}
+	s.clients[conf.Name] = client
+	if auth != nil {
+		s.auths[conf.Name] = auth
+	}
+	return nil
+}
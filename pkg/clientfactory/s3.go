@@ -0,0 +1,63 @@
+package clientfactory
+
+import (
+	"errors"
+	"net/url"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/minio/minio-go/v7/pkg/credentials"
+
+	"github.com/lueurxax/e2e/common"
+)
+
+// extra fields of s3 client
+const (
+	AccessKeyField = "access_key"
+	SecretKeyField = "secret_key"
+	RegionField    = "region"
+)
+
+// DefaultRegion region of s3 clients, it is set explicitly, so clients don't request location of buckets
+const DefaultRegion = "us-east-1"
+
+// S3Client client of s3 kind, url of config is endpoint with scheme, access_key and secret_key of extra fields
+// are credentials, email and password are used when they are absent, token is session token.
+// Buckets are addressed by path, timeout of config limits waiting of response headers.
+type S3Client struct {
+	*minio.Core
+	Region string
+}
+
+func newS3(conf common.Client, auth Authenticator) (interface{}, error) {
+	if auth != nil {
+		return nil, errors.New("s3 client is authenticated by keys, auth isn't supported")
+	}
+	endpoint, err := url.Parse(conf.Url)
+	if err != nil {
+		return nil, err
+	}
+	accessKey, secretKey := conf.ExtraString(AccessKeyField), conf.ExtraString(SecretKeyField)
+	if accessKey == "" {
+		accessKey, secretKey = conf.Email, conf.Password
+	}
+	region := conf.ExtraString(RegionField)
+	if region == "" {
+		region = DefaultRegion
+	}
+	transport, err := newTransport(conf)
+	if err != nil {
+		return nil, err
+	}
+	transport.ResponseHeaderTimeout = conf.Timeout.Duration()
+	core, err := minio.NewCore(endpoint.Host, &minio.Options{
+		Creds:        credentials.NewStaticV4(accessKey, secretKey, conf.Token),
+		Secure:       endpoint.Scheme == "https",
+		Transport:    transport,
+		Region:       region,
+		BucketLookup: minio.BucketLookupPath,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &S3Client{Core: core, Region: region}, nil
+}
@@ -0,0 +1,62 @@
+package clientfactory
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"os"
+
+	"github.com/lueurxax/e2e/common"
+)
+
+// newTLSConfig build tls config from PEM files of config
+func newTLSConfig(conf *common.ClientTLS) (*tls.Config, error) {
+	result := &tls.Config{MinVersion: tls.VersionTLS12}
+	if conf == nil {
+		return result, nil
+	}
+	result.ServerName = conf.ServerName
+	result.InsecureSkipVerify = conf.InsecureSkipVerify
+	if conf.CAFile != "" {
+		data, err := os.ReadFile(conf.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		result.RootCAs = x509.NewCertPool()
+		if !result.RootCAs.AppendCertsFromPEM(data) {
+			return nil, errors.New("ca file has no certificates")
+		}
+	}
+	if conf.CertFile != "" || conf.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(conf.CertFile, conf.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		result.Certificates = []tls.Certificate{cert}
+	}
+	return result, nil
+}
+
+// newTransport clone default transport with tls config of client
+func newTransport(conf common.Client) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if conf.TLS == nil {
+		return transport, nil
+	}
+	var err error
+	transport.TLSClientConfig, err = newTLSConfig(conf.TLS)
+	return transport, err
+}
+
+// requestHeaders headers of config with authorization by static token
+func requestHeaders(conf common.Client) map[string]string {
+	headers := make(map[string]string, len(conf.Headers)+1)
+	for key, value := range conf.Headers {
+		headers[key] = value
+	}
+	if conf.Token != "" {
+		headers["Authorization"] = "Bearer " + conf.Token
+	}
+	return headers
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/models"
+)
+
+// ParseScenario parse ad-hoc scenario from yaml of one test, decoding is strict like in config.
+// Secret references aren't resolved, because yaml comes from users of API, not from owners of runner.
+func ParseScenario(data []byte) (scenario models.Scenario, err error) {
+	doc := &yaml.Node{}
+	if err = yaml.Unmarshal(data, doc); err != nil {
+		return scenario, common.ErrInvalidConfig(err.Error())
+	}
+	if len(doc.Content) == 0 {
+		return scenario, common.ErrInvalidConfig("scenario is empty")
+	}
+	c := &config{root: doc.Content[0], files: map[*yaml.Node]string{}}
+	strict := &checker{files: c.files}
+	strict.check(c.root, reflect.TypeOf(models.Test{}))
+	if len(strict.issues) > 0 {
+		return scenario, common.ErrConfigIssues(strict.issues)
+	}
+	test := &models.Test{}
+	if err = c.root.Decode(test); err != nil {
+		return scenario, common.ErrInvalidConfig(err.Error())
+	}
+	v := &validation{c: c}
+	if test.Name == "" {
+		v.add(fmt.Errorf("name of scenario is required"), "name")
+	}
+	if err = test.Validate(); err != nil {
+		v.add(err)
+	}
+	c.validateCounts(v, test, nil)
+	if err = v.err(); err != nil {
+		return scenario, err
+	}
+	test.Prepare()
+	return newScenario(test), nil
+}
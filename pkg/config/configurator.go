@@ -1,47 +1,158 @@
 package config
 
 import (
-	"io/ioutil"
+	"context"
+	"os"
 	"path/filepath"
+	"reflect"
 
 	"gopkg.in/yaml.v3"
 
 	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/clientfactory"
 	"github.com/lueurxax/e2e/pkg/models"
+	"github.com/lueurxax/e2e/pkg/secrets"
+	"github.com/lueurxax/e2e/pkg/testerspool"
 )
 
 // Configurator read and init config for work with e2e framework
 type Configurator interface {
 	Read() (err error)
 	Validate() (err error)
+	ValidateTesters(pool testerspool.TestersPool) (err error)
 	Init()
 	GetScenarios() (scenarios []models.Scenario, err error)
-	Clients() (clients []common.Client)
+	Clients() (clients *clientfactory.Set, err error)
+	HTTPTesters() (testers []common.HTTPTester)
+	GRPCTesters() (testers []common.GRPCTester)
+	ScriptTesters() (testers []common.ScriptTester)
+	// PluginsDir directory of tester plugins resolved against config, empty without plugins
+	PluginsDir() (dir string)
+	// Files files and directories config was read from for watching of changes
+	Files() (files []string)
+	// Close release clients of config
+	Close() (err error)
 }
 
 type config struct {
 	configPath string
+	env        string
 	data       *models.Config
+	clients    *clientfactory.Set
+	// root merged yaml document and source files of its nodes for positions of issues
+	root    *yaml.Node
+	files   map[*yaml.Node]string
+	sources []string
 }
 
-func (c *config) Clients() []common.Client {
-	return c.data.Clients
+// Clients ready clients of config, they are built once by factories of their kinds
+func (c *config) Clients() (clients *clientfactory.Set, err error) {
+	if c.clients == nil {
+		c.clients, err = clientfactory.Build(c.data.Clients)
+	}
+	return c.clients, err
+}
+
+// Files unique files and directories config was read from, they are known also after failed read
+func (c *config) Files() []string {
+	files := make([]string, 0, len(c.sources))
+	seen := make(map[string]struct{}, len(c.sources))
+	for _, file := range c.sources {
+		if _, ok := seen[file]; !ok {
+			seen[file] = struct{}{}
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// Close release clients of config, if they were built
+func (c *config) Close() error {
+	if c.clients == nil {
+		return nil
+	}
+	return c.clients.Close()
+}
+
+// PluginsDir directory of tester plugins, relative directory is resolved against directory of config
+func (c *config) PluginsDir() string {
+	return c.resolvePath(c.data.PluginsDir)
+}
+
+// resolvePaths resolve relative paths against directory of config, nil for empty paths
+func (c *config) resolvePaths(paths []string) []string {
+	if len(paths) == 0 {
+		return nil
+	}
+	resolved := make([]string, len(paths))
+	for i, path := range paths {
+		resolved[i] = c.resolvePath(path)
+	}
+	return resolved
+}
+
+// resolvePath resolve relative path against directory of config
+func (c *config) resolvePath(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	base := c.configPath
+	if info, err := os.Stat(base); err != nil || !info.IsDir() {
+		base = filepath.Dir(base)
+	}
+	return filepath.Join(base, path)
+}
+
+func (c *config) HTTPTesters() []common.HTTPTester {
+	return c.data.HTTPTesters
+}
+
+// GRPCTesters configs of grpc testers, relative import paths, descriptor set and proto files without import paths
+// are resolved against directory of config
+func (c *config) GRPCTesters() []common.GRPCTester {
+	testers := make([]common.GRPCTester, len(c.data.GRPCTesters))
+	for i, tester := range c.data.GRPCTesters {
+		tester.ImportPaths = c.resolvePaths(tester.ImportPaths)
+		// proto files are names relative to import paths, if they are set
+		if len(tester.ImportPaths) == 0 {
+			tester.ProtoFiles = c.resolvePaths(tester.ProtoFiles)
+		}
+		tester.DescriptorSet = c.resolvePath(tester.DescriptorSet)
+		testers[i] = tester
+	}
+	return testers
+}
+
+// ScriptTesters configs of script testers, relative files of scripts are resolved against directory of config
+func (c *config) ScriptTesters() []common.ScriptTester {
+	testers := make([]common.ScriptTester, len(c.data.ScriptTesters))
+	for i, tester := range c.data.ScriptTesters {
+		tester.File = c.resolvePath(tester.File)
+		testers[i] = tester
+	}
+	return testers
 }
 
-// Read config for yaml file
+// Read config from yaml file or directory of yaml files with includes, overlay of environment
+// and extends of tests, secret references are resolved. Decoding is strict, unknown keys are issues.
 func (c *config) Read() (err error) {
-	var absPath string
-	absPath, err = filepath.Abs(c.configPath)
+	var l *loader
+	c.root, l, err = load(c.configPath, c.env)
+	c.sources = l.sources
 	if err != nil {
 		return
 	}
-	var data []byte
-	data, err = ioutil.ReadFile(absPath)
-	if err != nil {
+	c.files = l.files
+	if err = secrets.ResolveNode(context.Background(), c.root); err != nil {
 		return
 	}
+	strict := &checker{files: c.files}
+	strict.check(c.root, reflect.TypeOf(models.Config{}))
+	if len(strict.issues) > 0 {
+		return common.ErrConfigIssues(strict.issues)
+	}
 	c.data = &models.Config{}
-	err = yaml.Unmarshal(data, c.data)
+	err = c.root.Decode(c.data)
 	return
 }
 
@@ -52,42 +163,42 @@ func (c *config) Init() {
 	}
 }
 
-// Validate config
-func (c *config) Validate() (err error) {
+// GetScenarios scenarios names list
+func (c *config) GetScenarios() (scenarios []models.Scenario, err error) {
+	scenarios = make([]models.Scenario, len(c.data.Tests))
 	for i := range c.data.Tests {
-		if err = c.data.Tests[i].Validate(); err != nil {
-			return
-		}
+		scenarios[i] = newScenario(&c.data.Tests[i])
 	}
 	return
 }
 
-// GetScenarios scenarios names list
-func (c *config) GetScenarios() (scenarios []models.Scenario, err error) {
-	scenarios = make([]models.Scenario, len(c.data.Tests))
-	for i, scenario := range c.data.Tests {
-		data := models.Scenario{
-			Name:       scenario.Name,
-			Config:     &c.data.Tests[i],
-			BeforeTest: make([]models.Stage, len(scenario.BeforeTest)),
-		}
-		var shootCount int
-		if scenario.StressLoad != nil {
-			shootCount = scenario.StressLoad.GetShootCount()
-		} else {
-			shootCount = scenario.Repeat
-		}
-		for i, stageConf := range scenario.BeforeTest {
-			stageData := getStage(scenario.InitState.GlobalParams, stageConf, shootCount)
-			data.BeforeTest[i] = *stageData
-		}
-
-		data.Action = getStage(scenario.InitState.GlobalParams, scenario.Action, shootCount)
-		data.Check = getStage(scenario.InitState.GlobalParams, scenario.Check, shootCount)
-		data.AfterTest = getStage(scenario.InitState.GlobalParams, scenario.AfterTest, shootCount)
-		scenarios[i] = data
+// newScenario resolve stages of prepared test
+func newScenario(scenario *models.Test) models.Scenario {
+	data := models.Scenario{
+		Name:       scenario.Name,
+		Config:     scenario,
+		BeforeTest: make([]models.Stage, len(scenario.BeforeTest)),
 	}
-	return
+	var shootCount int
+	if scenario.StressLoad != nil {
+		shootCount = scenario.StressLoad.GetShootCount()
+	} else {
+		shootCount = scenario.Repeat
+	}
+	// with streaming ammo only action is stressed, other stages prepare and check one state
+	stagesCount := shootCount
+	if scenario.StressLoad != nil && scenario.StressLoad.IsStreaming() {
+		stagesCount = 1
+	}
+	for i, stageConf := range scenario.BeforeTest {
+		stageData := getStage(scenario.InitState.GlobalParams, stageConf, stagesCount)
+		data.BeforeTest[i] = *stageData
+	}
+
+	data.Action = getStage(scenario.InitState.GlobalParams, scenario.Action, shootCount)
+	data.Check = getStage(scenario.InitState.GlobalParams, scenario.Check, stagesCount)
+	data.AfterTest = getStage(scenario.InitState.GlobalParams, scenario.AfterTest, stagesCount)
+	return data
 }
 
 func getStage(globalParams map[string]interface{}, conf models.TestStage, stressLoadCount int) (st *models.Stage) {
@@ -108,9 +219,23 @@ func getStage(globalParams map[string]interface{}, conf models.TestStage, stress
 	return
 }
 
-// NewConfig construct Configurator
-func NewConfig(configPath string) Configurator {
-	return &config{
+// Option of Configurator
+type Option func(c *config)
+
+// WithEnv merge overlay of environment from envs/<env>.yaml near config over config
+func WithEnv(env string) Option {
+	return func(c *config) {
+		c.env = env
+	}
+}
+
+// NewConfig construct Configurator of yaml file or directory of yaml files
+func NewConfig(configPath string, opts ...Option) Configurator {
+	c := &config{
 		configPath: configPath,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
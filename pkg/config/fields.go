@@ -0,0 +1,63 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	unmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+	durationType    = reflect.TypeOf(time.Duration(0))
+)
+
+// yamlFields fields of struct by yaml keys like yaml.v3 decodes them: key of tag or lowercased name of field,
+// fields of inline structs are flattened, inline map accepts any other keys
+func yamlFields(t reflect.Type) (fields map[string]reflect.Type, order []string, anyKeys bool) {
+	fields = map[string]reflect.Type{}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		tag := field.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		name, flags, _ := strings.Cut(tag, ",")
+		if strings.Contains(flags, "inline") {
+			switch field.Type.Kind() {
+			case reflect.Map:
+				anyKeys = true
+			case reflect.Struct:
+				inline, inlineOrder, inlineAny := yamlFields(field.Type)
+				for _, key := range inlineOrder {
+					fields[key] = inline[key]
+				}
+				order = append(order, inlineOrder...)
+				anyKeys = anyKeys || inlineAny
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field.Type
+		order = append(order, name)
+	}
+	return
+}
+
+// customDecoded type decoded by its own UnmarshalYAML
+func customDecoded(t reflect.Type) bool {
+	return t.Implements(unmarshalerType) || reflect.PointerTo(t).Implements(unmarshalerType)
+}
+
+func deref(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,418 @@
+package config
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/lueurxax/e2e/common"
+)
+
+// keys of config handled by loader
+const (
+	includeKey  = "include"
+	extendsKey  = "extends"
+	abstractKey = "abstract"
+	nameKey     = "name"
+	testsKey    = "tests"
+)
+
+// EnvsDir directory of environment overlays near config, it is skipped in directory mode
+const EnvsDir = "envs"
+
+// loader merge config from files: lists of sections are concatenated, names of entries must be unique,
+// included files are loaded recursively by globs relative to including file
+type loader struct {
+	// files source file of every node for positions in errors
+	files map[*yaml.Node]string
+	// loading files in progress for detection of include cycles
+	loading map[string]bool
+	// loaded files, file included by other file of directory is loaded once
+	loaded map[string]bool
+	// sources files and directories config is read from, in order of reading
+	sources []string
+	// keys first nodes of top level keys, they keep positions of keys for issues
+	keys   []*yaml.Node
+	values map[string]*yaml.Node
+}
+
+func newLoader() *loader {
+	return &loader{
+		files:   map[*yaml.Node]string{},
+		loading: map[string]bool{},
+		loaded:  map[string]bool{},
+		values:  map[string]*yaml.Node{},
+	}
+}
+
+// load config file or all yaml files of directory in lexical order and overlay of environment,
+// loader keeps source files of nodes and sources of config, sources are kept on error, so fixed file is noticed
+func load(path, env string) (*yaml.Node, *loader, error) {
+	l := newLoader()
+	l.sources = append(l.sources, path)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, l, err
+	}
+	dir := filepath.Dir(path)
+	paths := []string{path}
+	if info.IsDir() {
+		dir = path
+		if paths, err = yamlFiles(path); err != nil {
+			return nil, l, err
+		}
+	}
+	for _, file := range paths {
+		if err = l.loadFile(file); err != nil {
+			return nil, l, err
+		}
+	}
+	root := l.root()
+	if env != "" {
+		l.sources = append(l.sources, filepath.Join(dir, EnvsDir))
+		if root, err = l.overlay(root, dir, env); err != nil {
+			return nil, l, err
+		}
+	}
+	if err = l.extend(root); err != nil {
+		return nil, l, err
+	}
+	return root, l, nil
+}
+
+// yamlFiles yaml files of directory and its subdirectories except directory of overlays
+func yamlFiles(dir string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != dir && entry.Name() == EnvsDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ext := filepath.Ext(path); ext == ".yaml" || ext == ".yml" {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	return paths, err
+}
+
+func (l *loader) loadFile(path string) error {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	if l.loading[abs] {
+		return common.ErrInvalidConfig(fmt.Sprintf("include cycle at %s", path))
+	}
+	if l.loaded[abs] {
+		return nil
+	}
+	l.loaded[abs] = true
+	l.sources = append(l.sources, path)
+	l.loading[abs] = true
+	defer delete(l.loading, abs)
+
+	doc, err := l.parseFile(path)
+	if err != nil || doc == nil {
+		return err
+	}
+	if doc.Kind != yaml.MappingNode {
+		return common.ErrInvalidConfig(fmt.Sprintf("%s:%d: config must be mapping", path, doc.Line))
+	}
+	for i := 0; i < len(doc.Content); i += 2 {
+		key, value := doc.Content[i], doc.Content[i+1]
+		if key.Value == includeKey {
+			if err = l.include(path, value); err != nil {
+				return err
+			}
+			continue
+		}
+		if err = l.add(path, key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// include files matched by globs, globs are relative to including file
+func (l *loader) include(path string, globs *yaml.Node) error {
+	patterns := []*yaml.Node{globs}
+	if globs.Kind == yaml.SequenceNode {
+		patterns = globs.Content
+	}
+	for _, pattern := range patterns {
+		if pattern.Kind != yaml.ScalarNode {
+			return common.ErrInvalidConfig(fmt.Sprintf("%s:%d: include must be glob or list of globs", path, pattern.Line))
+		}
+		glob := pattern.Value
+		if !filepath.IsAbs(glob) {
+			glob = filepath.Join(filepath.Dir(path), glob)
+		}
+		matches, err := filepath.Glob(glob)
+		if err != nil {
+			return common.ErrInvalidConfig(fmt.Sprintf("%s:%d: %s", path, pattern.Line, err))
+		}
+		if len(matches) == 0 {
+			return common.ErrInvalidConfig(fmt.Sprintf("%s:%d: include %s matches no files", path, pattern.Line, pattern.Value))
+		}
+		sort.Strings(matches)
+		for _, match := range matches {
+			if err = l.loadFile(match); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// add top level value of file, entries of lists are concatenated, other values must be set once
+func (l *loader) add(path string, keyNode, value *yaml.Node) error {
+	key := keyNode.Value
+	current, ok := l.values[key]
+	if !ok {
+		l.keys = append(l.keys, keyNode)
+		current = value
+		if value.Kind == yaml.SequenceNode {
+			current = &yaml.Node{Kind: yaml.SequenceNode, Tag: value.Tag, Line: value.Line, Column: value.Column}
+		}
+		l.files[current] = path
+		l.values[key] = current
+	}
+	if value.Kind != yaml.SequenceNode || current.Kind != yaml.SequenceNode {
+		if current == value {
+			return nil
+		}
+		return common.ErrInvalidConfig(fmt.Sprintf("%s at %s:%d conflicts with %s:%d",
+			key, path, value.Line, l.files[current], current.Line))
+	}
+	for _, entry := range value.Content {
+		if name := entryName(entry); name != "" {
+			if existing := findEntry(current, name); existing != nil {
+				return common.ErrInvalidConfig(fmt.Sprintf("%s %s at %s:%d conflicts with %s:%d",
+					key, name, path, entry.Line, l.files[existing], existing.Line))
+			}
+		}
+		l.files[entry] = path
+		current.Content = append(current.Content, entry)
+	}
+	return nil
+}
+
+// root mapping of merged files in order of keys
+func (l *loader) root() *yaml.Node {
+	root := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	for _, key := range l.keys {
+		root.Content = append(root.Content, key, l.values[key.Value])
+	}
+	return root
+}
+
+// overlay merge overlay of environment over merged config, mappings are merged recursively,
+// entries of top level lists are merged by names, other values are replaced
+func (l *loader) overlay(root *yaml.Node, dir, env string) (*yaml.Node, error) {
+	path := filepath.Join(dir, EnvsDir, env+".yaml")
+	if yml := filepath.Join(dir, EnvsDir, env+".yml"); !exists(path) && exists(yml) {
+		path = yml
+	}
+	doc, err := l.parseFile(path)
+	if err != nil {
+		return nil, common.ErrInvalidConfig(fmt.Sprintf("overlay of environment %s: %s", env, err))
+	}
+	if doc == nil {
+		return root, nil
+	}
+	if doc.Kind != yaml.MappingNode {
+		return nil, common.ErrInvalidConfig(fmt.Sprintf("%s:%d: overlay must be mapping", path, doc.Line))
+	}
+	for i := 0; i < len(doc.Content); i += 2 {
+		key, value := doc.Content[i], doc.Content[i+1]
+		current := mappingValue(root, key.Value)
+		switch {
+		case current == nil:
+			root.Content = append(root.Content, key, value)
+		case current.Kind == yaml.SequenceNode && value.Kind == yaml.SequenceNode:
+			for _, entry := range value.Content {
+				existing := findEntry(current, entryName(entry))
+				if existing == nil {
+					current.Content = append(current.Content, entry)
+					continue
+				}
+				*existing = *mergeNodes(existing, entry)
+			}
+		default:
+			*current = *mergeNodes(current, value)
+		}
+	}
+	return root, nil
+}
+
+// extend resolve extends of tests: test inherits params and stages of base test, its own values win,
+// abstract tests are only bases and are removed from config
+func (l *loader) extend(root *yaml.Node) error {
+	tests := mappingValue(root, testsKey)
+	if tests == nil || tests.Kind != yaml.SequenceNode {
+		return nil
+	}
+	resolved := map[*yaml.Node]*yaml.Node{}
+	var resolve func(test *yaml.Node, chain []string) (*yaml.Node, error)
+	resolve = func(test *yaml.Node, chain []string) (*yaml.Node, error) {
+		if result, ok := resolved[test]; ok {
+			return result, nil
+		}
+		result := test
+		if base := mappingValue(test, extendsKey); base != nil {
+			name := entryName(test)
+			for _, previous := range chain {
+				if previous == name {
+					return nil, common.ErrInvalidConfig(fmt.Sprintf("test %s at %s:%d has extends cycle %s",
+						name, l.files[test], test.Line, strings.Join(append(chain, name), " -> ")))
+				}
+			}
+			parent := findEntry(tests, base.Value)
+			if parent == nil {
+				return nil, common.ErrInvalidConfig(fmt.Sprintf("test %s at %s:%d extends unknown test %s",
+					name, l.files[test], base.Line, base.Value))
+			}
+			parentResolved, err := resolve(parent, append(chain, name))
+			if err != nil {
+				return nil, err
+			}
+			// abstract isn't inherited
+			result = l.copyNode(parentResolved)
+			deleteKey(result, abstractKey)
+			result = mergeNodes(result, test)
+			deleteKey(result, extendsKey)
+		}
+		resolved[test] = result
+		return result, nil
+	}
+	content := make([]*yaml.Node, 0, len(tests.Content))
+	for _, test := range tests.Content {
+		result, err := resolve(test, nil)
+		if err != nil {
+			return err
+		}
+		if abstract := mappingValue(result, abstractKey); abstract != nil {
+			if abstract.Value == "true" {
+				continue
+			}
+			deleteKey(result, abstractKey)
+		}
+		content = append(content, result)
+	}
+	tests.Content = content
+	return nil
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// parseFile parse yaml document of file and track file of its nodes, empty file has no document
+func (l *loader) parseFile(path string) (*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc yaml.Node
+	if err = yaml.Unmarshal(data, &doc); err != nil {
+		return nil, common.ErrInvalidConfig(fmt.Sprintf("%s: %s", path, err))
+	}
+	if doc.Kind == 0 || len(doc.Content) == 0 {
+		return nil, nil
+	}
+	l.track(path, doc.Content[0])
+	return doc.Content[0], nil
+}
+
+func (l *loader) track(path string, node *yaml.Node) {
+	l.files[node] = path
+	for _, child := range node.Content {
+		l.track(path, child)
+	}
+}
+
+// mergeNodes merge mappings recursively, other values of over replace values of base
+func mergeNodes(base, over *yaml.Node) *yaml.Node {
+	if base.Kind != yaml.MappingNode || over.Kind != yaml.MappingNode {
+		return over
+	}
+	for i := 0; i < len(over.Content); i += 2 {
+		key, value := over.Content[i], over.Content[i+1]
+		found := false
+		for j := 0; j < len(base.Content); j += 2 {
+			if base.Content[j].Value == key.Value {
+				base.Content[j+1] = mergeNodes(base.Content[j+1], value)
+				found = true
+				break
+			}
+		}
+		if !found {
+			base.Content = append(base.Content, key, value)
+		}
+	}
+	return base
+}
+
+// copyNode deep copy of node, copies keep source files
+func (l *loader) copyNode(node *yaml.Node) *yaml.Node {
+	result := *node
+	result.Content = make([]*yaml.Node, len(node.Content))
+	for i, child := range node.Content {
+		result.Content[i] = l.copyNode(child)
+	}
+	l.files[&result] = l.files[node]
+	return &result
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func deleteKey(node *yaml.Node, key string) {
+	for i := 0; i < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			node.Content = append(node.Content[:i], node.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// entryName name of entry of list, empty for entry without name
+func entryName(entry *yaml.Node) string {
+	if name := mappingValue(entry, nameKey); name != nil {
+		return name.Value
+	}
+	return ""
+}
+
+func findEntry(list *yaml.Node, name string) *yaml.Node {
+	if name == "" {
+		return nil
+	}
+	for _, entry := range list.Content {
+		if entryName(entry) == name {
+			return entry
+		}
+	}
+	return nil
+}
@@ -0,0 +1,287 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeFiles write files of config to temporary directory, names of files are relative to directory
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(strings.TrimLeft(data, "\n")), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+// readConfig read config of path relative to directory
+func readConfig(dir, path, env string) (*config, error) {
+	c := NewConfig(filepath.Join(dir, path), WithEnv(env)).(*config)
+	return c, c.Read()
+}
+
+func TestLoaderMerge(t *testing.T) {
+	type test struct {
+		name   string
+		repeat int
+		params map[string]interface{}
+		action string
+	}
+	tests := []struct {
+		name       string
+		files      map[string]string
+		path       string
+		env        string
+		tests      []test
+		pluginsDir string
+	}{
+		{
+			name: "includes concatenate lists",
+			files: map[string]string{
+				"main.yaml": `
+include: tests/*.yaml
+plugins_dir: plugins
+tests:
+  - name: main
+`,
+				"tests/b.yaml": `
+tests:
+  - name: b
+`,
+				"tests/a.yaml": `
+tests:
+  - name: a
+`,
+			},
+			path:       "main.yaml",
+			tests:      []test{{name: "a"}, {name: "b"}, {name: "main"}},
+			pluginsDir: "plugins",
+		},
+		{
+			name: "directory in lexical order without overlays",
+			files: map[string]string{
+				"b.yaml":            "tests:\n  - name: b\n",
+				"a/a.yaml":          "tests:\n  - name: a\n",
+				"envs/staging.yaml": "tests:\n  - name: staging\n",
+			},
+			path:  ".",
+			tests: []test{{name: "a"}, {name: "b"}},
+		},
+		{
+			name: "overlay wins over base",
+			files: map[string]string{
+				"main.yaml": `
+plugins_dir: plugins
+tests:
+  - name: a
+    repeat: 1
+    params: {host: base, user: bob}
+    action: {name: Login}
+`,
+				"envs/staging.yaml": `
+plugins_dir: staging
+tests:
+  - name: a
+    repeat: 3
+    params: {host: staging}
+  - name: c
+`,
+			},
+			path: "main.yaml",
+			env:  "staging",
+			tests: []test{
+				{name: "a", repeat: 3, params: map[string]interface{}{"host": "staging", "user": "bob"}, action: "Login"},
+				{name: "c"},
+			},
+			pluginsDir: "staging",
+		},
+		{
+			name: "test wins over base test",
+			files: map[string]string{
+				"main.yaml": `
+tests:
+  - name: base
+    abstract: true
+    repeat: 2
+    params: {host: base, user: bob}
+    action: {name: Login}
+  - name: child
+    extends: base
+    params: {user: alice}
+  - name: grandchild
+    extends: child
+    repeat: 5
+`,
+			},
+			path: "main.yaml",
+			tests: []test{
+				{name: "child", repeat: 2, params: map[string]interface{}{"host": "base", "user": "alice"}, action: "Login"},
+				{name: "grandchild", repeat: 5, params: map[string]interface{}{"host": "base", "user": "alice"}, action: "Login"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, tt.files)
+			c, err := readConfig(dir, tt.path, tt.env)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := c.data.PluginsDir; got != tt.pluginsDir {
+				t.Fatalf("expected plugins dir %q, got %q", tt.pluginsDir, got)
+			}
+			if len(c.data.Tests) != len(tt.tests) {
+				t.Fatalf("expected %d tests, got %+v", len(tt.tests), c.data.Tests)
+			}
+			for i, expected := range tt.tests {
+				got := c.data.Tests[i]
+				if got.Name != expected.name || got.Repeat != expected.repeat || got.Action.Name != expected.action {
+					t.Fatalf("expected test %+v, got %+v", expected, got)
+				}
+				if len(expected.params) > 0 && !reflect.DeepEqual(got.Params, expected.params) {
+					t.Fatalf("expected params %v, got %v", expected.params, got.Params)
+				}
+			}
+		})
+	}
+}
+
+func TestLoaderConflicts(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		path  string
+		env   string
+		// err expected error, {dir} is replaced by directory of config
+		err string
+	}{
+		{
+			name: "duplicated test of included file",
+			files: map[string]string{
+				"main.yaml": "tests:\n  - name: a\ninclude: other.yaml\n",
+				"other.yaml": `
+clients: []
+tests:
+  - name: a
+`,
+			},
+			path: "main.yaml",
+			err:  "tests a at {dir}/other.yaml:3 conflicts with {dir}/main.yaml:2",
+		},
+		{
+			name: "value set twice",
+			files: map[string]string{
+				"a.yaml": "plugins_dir: a\n",
+				"b.yaml": "# b\nplugins_dir: b\n",
+			},
+			path: ".",
+			err:  "plugins_dir at {dir}/b.yaml:2 conflicts with {dir}/a.yaml:1",
+		},
+		{
+			name: "include cycle",
+			files: map[string]string{
+				"main.yaml":  "include: other.yaml\n",
+				"other.yaml": "include: main.yaml\n",
+			},
+			path: "main.yaml",
+			err:  "include cycle at {dir}/main.yaml",
+		},
+		{
+			name:  "include without files",
+			files: map[string]string{"main.yaml": "tests: []\ninclude: [tests/*.yaml]\n"},
+			path:  "main.yaml",
+			err:   "{dir}/main.yaml:2: include tests/*.yaml matches no files",
+		},
+		{
+			name: "extends unknown test",
+			files: map[string]string{"main.yaml": `
+tests:
+  - name: a
+  - name: b
+    extends: missing
+`},
+			path: "main.yaml",
+			err:  "test b at {dir}/main.yaml:4 extends unknown test missing",
+		},
+		{
+			name: "extends cycle",
+			files: map[string]string{"main.yaml": `
+tests:
+  - name: a
+    extends: b
+  - name: b
+    extends: a
+`},
+			path: "main.yaml",
+			err:  "test a at {dir}/main.yaml:2 has extends cycle a -> b -> a",
+		},
+		{
+			name:  "missing overlay",
+			files: map[string]string{"main.yaml": "tests: []\n"},
+			path:  "main.yaml",
+			env:   "staging",
+			err:   "overlay of environment staging",
+		},
+		{
+			name: "overlay of wrong kind",
+			files: map[string]string{
+				"main.yaml":         "tests: []\n",
+				"envs/staging.yaml": "# staging\n- name: a\n",
+			},
+			path: "main.yaml",
+			env:  "staging",
+			err:  "{dir}/envs/staging.yaml:2: overlay must be mapping",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, tt.files)
+			_, err := readConfig(dir, tt.path, tt.env)
+			expected := strings.ReplaceAll(tt.err, "{dir}", dir)
+			if err == nil || !strings.Contains(err.Error(), expected) {
+				t.Fatalf("expected error %q, got %v", expected, err)
+			}
+		})
+	}
+}
+
+func TestLoaderSources(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"main.yaml":         "include: tests/*.yaml\n",
+		"tests/a.yaml":      "tests:\n  - name: a\n",
+		"envs/staging.yaml": "plugins_dir: staging\n",
+	})
+	c, err := readConfig(dir, "main.yaml", "staging")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []string{
+		filepath.Join(dir, "main.yaml"),
+		filepath.Join(dir, "tests/a.yaml"),
+		filepath.Join(dir, EnvsDir),
+	}
+	if files := c.Files(); !reflect.DeepEqual(files, expected) {
+		t.Fatalf("expected files %v, got %v", expected, files)
+	}
+
+	// sources of failed read are known, so fixed file is noticed by watching
+	if err = os.WriteFile(filepath.Join(dir, "tests/a.yaml"), []byte("tests: {"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if c, err = readConfig(dir, "main.yaml", ""); err == nil {
+		t.Fatal("expected error of invalid yaml")
+	}
+	if files := c.Files(); !reflect.DeepEqual(files, expected[:2]) {
+		t.Fatalf("expected files %v of failed read, got %v", expected[:2], files)
+	}
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+
+	"github.com/lueurxax/e2e/pkg/models"
+)
+
+// secretReferencePattern pattern of value, which is only secret reference
+const secretReferencePattern = `^\$\{[a-zA-Z][a-zA-Z0-9_-]*:[^}]*\}$`
+
+// SchemaID identifier of JSON Schema of config
+const SchemaID = "https://github.com/lueurxax/e2e/config.schema.json"
+
+// JSONSchema JSON Schema of config for autocompletion and validation in editors,
+// it is generated from yaml fields of config models, so it follows strict decoding of config
+func JSONSchema() ([]byte, error) {
+	schema := typeSchema(reflect.TypeOf(models.Config{}))
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["$id"] = SchemaID
+	schema["title"] = "e2e config"
+	// loader keys aren't fields of config
+	properties := schema["properties"].(map[string]interface{})
+	properties[includeKey] = map[string]interface{}{
+		"description": "globs of included files relative to including file",
+		"oneOf": []interface{}{
+			map[string]interface{}{"type": "string"},
+			map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+		},
+	}
+	test := properties[testsKey].(map[string]interface{})["items"].(map[string]interface{})
+	test["properties"].(map[string]interface{})[extendsKey] = map[string]interface{}{
+		"type":        "string",
+		"description": "name of base test, params and stages of base are inherited",
+	}
+	test["properties"].(map[string]interface{})[abstractKey] = map[string]interface{}{
+		"type":        "boolean",
+		"description": "abstract test is only base of other tests and isn't run",
+	}
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+func typeSchema(t reflect.Type) map[string]interface{} {
+	t = deref(t)
+	if t == durationType || customDecoded(t) {
+		// durations are duration strings like 90s, custom durations accept integer seconds too
+		return map[string]interface{}{"type": []string{"string", "integer"}}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return scalarSchema("boolean")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return scalarSchema("integer")
+	case reflect.Float32, reflect.Float64:
+		return scalarSchema("number")
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": typeSchema(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": typeSchema(t.Elem())}
+	case reflect.Struct:
+		fields, order, anyKeys := yamlFields(t)
+		properties := make(map[string]interface{}, len(fields))
+		for _, key := range order {
+			properties[key] = typeSchema(fields[key])
+		}
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": anyKeys,
+		}
+	}
+	return map[string]interface{}{}
+}
+
+// scalarSchema not string scalar may be secret reference, which is resolved to value of type
+func scalarSchema(typ string) map[string]interface{} {
+	return map[string]interface{}{"anyOf": []interface{}{
+		map[string]interface{}{"type": typ},
+		map[string]interface{}{"type": "string", "pattern": secretReferencePattern},
+	}}
+}
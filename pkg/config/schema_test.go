@@ -0,0 +1,68 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestJSONSchema(t *testing.T) {
+	data, err := JSONSchema()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]interface{}
+	if err = json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+	if schema["$id"] != SchemaID {
+		t.Fatalf("unexpected id of schema %v", schema["$id"])
+	}
+	// lookup value of path of nested objects of schema
+	lookup := func(path ...string) interface{} {
+		var value interface{} = schema
+		for _, key := range path {
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				t.Fatalf("%v isn't object at %s", path, key)
+			}
+			value = object[key]
+		}
+		return value
+	}
+	test := []string{"properties", "tests", "items"}
+	tests := []struct {
+		name  string
+		path  []string
+		value interface{}
+	}{
+		{name: "unknown keys of config", path: []string{"additionalProperties"}, value: false},
+		{name: "unknown keys of test", path: append(test, "additionalProperties"), value: false},
+		{name: "any keys of params", path: append(test, "properties", "params", "type"), value: "object"},
+		{name: "loader key of config", path: []string{"properties", "include", "description"},
+			value: "globs of included files relative to including file"},
+		{name: "loader key of test", path: append(test, "properties", "extends", "type"), value: "string"},
+		{name: "abstract test", path: append(test, "properties", "abstract", "type"), value: "boolean"},
+		{name: "string", path: append(test, "properties", "name", "type"), value: "string"},
+		{
+			name:  "duration",
+			path:  []string{"properties", "http_testers", "items", "properties", "timeout", "type"},
+			value: []interface{}{"string", "integer"},
+		},
+		{
+			name: "integer or secret reference",
+			path: append(test, "properties", "repeat", "anyOf"),
+			value: []interface{}{
+				map[string]interface{}{"type": "integer"},
+				map[string]interface{}{"type": "string", "pattern": secretReferencePattern},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if value := lookup(tt.path...); !reflect.DeepEqual(value, tt.value) {
+				t.Fatalf("expected %v, got %v", tt.value, value)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxSuggestionDistance maximal edit distance of suggested key for unknown key
+const maxSuggestionDistance = 2
+
+// checker strict check of config: keys of mappings must be yaml fields of models
+type checker struct {
+	files  map[*yaml.Node]string
+	issues []string
+}
+
+func (c *checker) issue(node *yaml.Node, format string, args ...interface{}) {
+	c.issues = append(c.issues, position(c.files, node)+fmt.Sprintf(format, args...))
+}
+
+// check keys of mappings of node against type, types decoded by their own UnmarshalYAML aren't checked
+func (c *checker) check(node *yaml.Node, t reflect.Type) {
+	t = deref(t)
+	if customDecoded(t) {
+		return
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			if node.Tag != "!!null" {
+				c.issue(node, "expected mapping, got %s", node.ShortTag())
+			}
+			return
+		}
+		fields, order, anyKeys := yamlFields(t)
+		for i := 0; i < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			field, ok := fields[key.Value]
+			if ok {
+				c.check(value, field)
+				continue
+			}
+			if anyKeys {
+				continue
+			}
+			if suggestion := suggest(key.Value, order); suggestion != "" {
+				c.issue(key, "unknown key %s, did you mean %s", key.Value, suggestion)
+				continue
+			}
+			c.issue(key, "unknown key %s", key.Value)
+		}
+	case reflect.Slice, reflect.Array:
+		if node.Kind == yaml.SequenceNode {
+			for _, item := range node.Content {
+				c.check(item, t.Elem())
+			}
+		}
+	case reflect.Map:
+		if node.Kind == yaml.MappingNode {
+			for i := 1; i < len(node.Content); i += 2 {
+				c.check(node.Content[i], t.Elem())
+			}
+		}
+	}
+}
+
+// position file and line of node prefix of issue
+func position(files map[*yaml.Node]string, node *yaml.Node) string {
+	if node == nil {
+		return ""
+	}
+	if file, ok := files[node]; ok {
+		return fmt.Sprintf("%s:%d: ", file, node.Line)
+	}
+	return fmt.Sprintf("line %d: ", node.Line)
+}
+
+// suggest closest known key to misspelled key
+func suggest(key string, known []string) string {
+	candidates := make([]string, 0)
+	best := maxSuggestionDistance + 1
+	for _, candidate := range known {
+		distance := editDistance(key, candidate)
+		switch {
+		case distance < best:
+			best, candidates = distance, []string{candidate}
+		case distance == best:
+			candidates = append(candidates, candidate)
+		}
+	}
+	if best > maxSuggestionDistance {
+		return ""
+	}
+	sort.Strings(candidates)
+	return candidates[0]
+}
+
+// editDistance Levenshtein distance of strings
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
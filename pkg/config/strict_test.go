@@ -0,0 +1,117 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// configIssues issues of config error
+func configIssues(t *testing.T, err error) []string {
+	t.Helper()
+	issues, ok := err.(interface{ Issues() []string })
+	if !ok {
+		t.Fatalf("expected issues of config, got %v", err)
+	}
+	return issues.Issues()
+}
+
+func TestStrictDecoding(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		// issues expected issues, {file} is replaced by file of config
+		issues []string
+	}{
+		{
+			name: "known keys",
+			data: `
+clients:
+  - name: api
+    url: http://localhost
+    extra: {bucket: data}
+tests:
+  - name: a
+    params: {any: key}
+    action: {name: Login, client: api}
+`,
+		},
+		{
+			name: "misspelled keys",
+			data: `
+client: []
+tests:
+  - name: a
+    before_tests: []
+    action: {nme: Login}
+`,
+			issues: []string{
+				"{file}:1: unknown key client, did you mean clients",
+				"{file}:4: unknown key before_tests, did you mean before_test",
+				"{file}:5: unknown key nme, did you mean name",
+			},
+		},
+		{
+			name: "unknown key without suggestion",
+			data: `
+tests:
+  - name: a
+    scenario: b
+`,
+			issues: []string{"{file}:3: unknown key scenario"},
+		},
+		{
+			name: "scalar instead of mapping",
+			data: `
+tests:
+  - name: a
+    action: Login
+    check:
+`,
+			issues: []string{"{file}:3: expected mapping, got !!str"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{"main.yaml": tt.data})
+			_, err := readConfig(dir, "main.yaml", "")
+			if len(tt.issues) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			issues := configIssues(t, err)
+			expected := make([]string, len(tt.issues))
+			for i, issue := range tt.issues {
+				expected[i] = strings.ReplaceAll(issue, "{file}", dir+"/main.yaml")
+			}
+			if !reflect.DeepEqual(issues, expected) {
+				t.Fatalf("expected issues %q, got %q", expected, issues)
+			}
+		})
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	known := []string{"before_test", "action", "check", "after_test", "name", "params"}
+	tests := []struct {
+		key        string
+		suggestion string
+	}{
+		{key: "before_tests", suggestion: "before_test"},
+		{key: "aftertest", suggestion: "after_test"},
+		{key: "chek", suggestion: "check"},
+		{key: "nam", suggestion: "name"},
+		// equally close keys are suggested in lexical order
+		{key: "acheck", suggestion: "check"},
+		{key: "scenario", suggestion: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			if suggestion := suggest(tt.key, known); suggestion != tt.suggestion {
+				t.Fatalf("expected suggestion %q, got %q", tt.suggestion, suggestion)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/models"
+	"github.com/lueurxax/e2e/pkg/testerspool"
+)
+
+// validation collect issues of config with positions of values
+type validation struct {
+	c      *config
+	issues []string
+}
+
+// add issue at value of path in config
+func (v *validation) add(err error, path ...string) {
+	v.issues = append(v.issues, position(v.c.files, v.c.node(path...))+err.Error())
+}
+
+func (v *validation) addf(path []string, format string, args ...interface{}) {
+	v.add(fmt.Errorf(format, args...), path...)
+}
+
+func (v *validation) err() error {
+	if len(v.issues) == 0 {
+		return nil
+	}
+	return common.ErrConfigIssues(v.issues)
+}
+
+// Validate config: clients are built, testers, tests and stages are checked, all issues are reported
+func (c *config) Validate() (err error) {
+	v := &validation{c: c}
+	if _, err = c.Clients(); err != nil {
+		v.add(err, "clients")
+	}
+	for i := range c.data.HTTPTesters {
+		if err = c.data.HTTPTesters[i].Validate(); err != nil {
+			v.add(err, "http_testers", strconv.Itoa(i))
+		}
+	}
+	for i := range c.data.GRPCTesters {
+		if err = c.data.GRPCTesters[i].Validate(); err != nil {
+			v.add(err, "grpc_testers", strconv.Itoa(i))
+		}
+	}
+	for i := range c.data.ScriptTesters {
+		if err = c.data.ScriptTesters[i].Validate(); err != nil {
+			v.add(err, "script_testers", strconv.Itoa(i))
+		}
+	}
+	clients := make(map[string]struct{}, len(c.data.Clients))
+	for _, client := range c.data.Clients {
+		clients[client.Name] = struct{}{}
+	}
+	names := make(map[string]struct{}, len(c.data.Tests))
+	for i := range c.data.Tests {
+		test := &c.data.Tests[i]
+		path := []string{testsKey, strconv.Itoa(i)}
+		if _, ok := names[test.Name]; ok {
+			v.addf(path, "duplicated test %s", test.Name)
+		}
+		names[test.Name] = struct{}{}
+		if err = test.Validate(); err != nil {
+			v.add(err, path...)
+		}
+		c.validateCounts(v, test, path)
+		c.forStages(test, func(stage *models.TestStage, stagePath ...string) {
+			if _, ok := clients[stage.Client]; stage.Client != "" && !ok {
+				v.addf(append(append(path, stagePath...), "client"), "unknown client %s", stage.Client)
+			}
+		})
+	}
+	return v.err()
+}
+
+// ValidateTesters check that testers of stages are in pool
+func (c *config) ValidateTesters(pool testerspool.TestersPool) error {
+	v := &validation{c: c}
+	for i := range c.data.Tests {
+		path := []string{testsKey, strconv.Itoa(i)}
+		c.forStages(&c.data.Tests[i], func(stage *models.TestStage, stagePath ...string) {
+			if stage.Name == "" {
+				return
+			}
+			if _, err := pool.Get(stage.Name); err != nil {
+				v.addf(append(append(path, stagePath...), "name"), "unknown tester %s", stage.Name)
+			}
+		})
+	}
+	return v.err()
+}
+
+func (c *config) validateCounts(v *validation, test *models.Test, path []string) {
+	counts := []struct {
+		key   []string
+		value int
+	}{
+		{[]string{"repeat"}, test.Repeat},
+		{[]string{"waiter_delay_milliseconds"}, test.WaiterDelayMilliseconds},
+	}
+	if load := test.StressLoad; load != nil {
+		counts = append(counts, []struct {
+			key   []string
+			value int
+		}{
+			{[]string{"stress_load", "instances"}, load.Instances},
+			{[]string{"stress_load", "from"}, load.From},
+			{[]string{"stress_load", "to"}, load.To},
+		}...)
+	}
+	for _, count := range counts {
+		if count.value < 0 {
+			v.addf(append(append([]string{}, path...), count.key...), "%s is negative", count.key[len(count.key)-1])
+		}
+	}
+}
+
+// forStages call function for every stage of test with path of stage in test
+func (c *config) forStages(test *models.Test, f func(stage *models.TestStage, path ...string)) {
+	for i := range test.BeforeTest {
+		f(&test.BeforeTest[i], "before_test", strconv.Itoa(i))
+	}
+	f(&test.Action, "action")
+	f(&test.Check, "check")
+	f(&test.AfterTest, "after_test")
+}
+
+// node value of path in config, keys of mappings and indexes of lists, deepest found value for missing path
+func (c *config) node(path ...string) *yaml.Node {
+	node := c.root
+	if node == nil {
+		return nil
+	}
+	for _, key := range path {
+		var next *yaml.Node
+		switch node.Kind {
+		case yaml.MappingNode:
+			next = mappingValue(node, key)
+		case yaml.SequenceNode:
+			if i, err := strconv.Atoi(key); err == nil && i < len(node.Content) {
+				next = node.Content[i]
+			}
+		}
+		if next == nil {
+			return node
+		}
+		node = next
+	}
+	return node
+}
@@ -0,0 +1,129 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/lueurxax/e2e/pkg/models"
+	"github.com/lueurxax/e2e/pkg/testerspool"
+)
+
+type tester struct {
+	models.Tester
+	name string
+}
+
+func (t *tester) MethodName() string {
+	return t.name
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		// issues expected issues, {file} is replaced by file of config
+		issues []string
+	}{
+		{
+			name: "valid config",
+			data: `
+clients:
+  - name: api
+    url: http://localhost
+tests:
+  - name: a
+    repeat: 2
+    action: {name: Login, client: api}
+`,
+		},
+		{
+			name: "issues of tests",
+			data: `
+clients:
+  - name: api
+    url: http://localhost
+tests:
+  - name: a
+    action: {name: Login, client: web}
+  - name: b
+    repeat: -1
+    before_test:
+      - {name: Login, client: api}
+      - {name: Login, client: db}
+  - name: c
+    repeat: 2
+    stress_load:
+      instances: -2
+`,
+			issues: []string{
+				"{file}:6: unknown client web",
+				"{file}:8: repeat is negative",
+				"{file}:11: unknown client db",
+				"{file}:12: config is invalid, reason: cannot use stress load with repeated requests",
+				"{file}:15: instances is negative",
+			},
+		},
+		{
+			name: "invalid testers",
+			data: `
+http_testers:
+  - name: GetUser
+grpc_testers:
+  - name: GetItem
+    method: items.Items/Get
+`,
+			issues: []string{
+				"{file}:2: config is invalid, reason: http tester GetUser without url",
+				"{file}:4: config is invalid, reason: grpc tester GetItem requires exactly one of proto_files, " +
+					"descriptor_set or reflection",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{"main.yaml": tt.data})
+			c, err := readConfig(dir, "main.yaml", "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() { _ = c.Close() }()
+			err = c.Validate()
+			if len(tt.issues) == 0 {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			issues := configIssues(t, err)
+			expected := make([]string, len(tt.issues))
+			for i, issue := range tt.issues {
+				expected[i] = strings.ReplaceAll(issue, "{file}", dir+"/main.yaml")
+			}
+			if !reflect.DeepEqual(issues, expected) {
+				t.Fatalf("expected issues %q, got %q", expected, issues)
+			}
+		})
+	}
+}
+
+func TestValidateTesters(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"main.yaml": `
+tests:
+  - name: a
+    before_test:
+      - name: Login
+    action: {name: GetUser}
+    check: {name: CheckUser}
+`})
+	c, err := readConfig(dir, "main.yaml", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := testerspool.NewTestersPool([]models.Tester{&tester{name: "Login"}, &tester{name: "GetUser"}})
+	issues := configIssues(t, c.ValidateTesters(pool))
+	expected := []string{dir + "/main.yaml:6: unknown tester CheckUser"}
+	if !reflect.DeepEqual(issues, expected) {
+		t.Fatalf("expected issues %q, got %q", expected, issues)
+	}
+}
@@ -0,0 +1,88 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultWatchInterval default interval of polling of config files
+const DefaultWatchInterval = 2 * time.Second
+
+// fingerprint modification times and sizes of files and their directories,
+// directories notice added and removed files of directory mode
+type fingerprint map[string]string
+
+func newFingerprint(files []string) fingerprint {
+	result := fingerprint{}
+	for _, file := range files {
+		for _, path := range []string{file, filepath.Dir(file)} {
+			if _, ok := result[path]; ok {
+				continue
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				result[path] = "absent"
+				continue
+			}
+			result[path] = fmt.Sprintf("%d/%d", info.ModTime().UnixNano(), info.Size())
+		}
+	}
+	return result
+}
+
+func (f fingerprint) equal(other fingerprint) bool {
+	if len(f) != len(other) {
+		return false
+	}
+	for path, value := range f {
+		if other[path] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// Watch poll files returned by function until context is done and call function on their change.
+// Files are requested on every poll, so files of reloaded config are watched after reload.
+func Watch(ctx context.Context, interval time.Duration, files func() []string, onChange func()) {
+	if interval <= 0 {
+		interval = DefaultWatchInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	last := newFingerprint(files())
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		watched := files()
+		current := newFingerprint(watched)
+		if current.equal(last) {
+			continue
+		}
+		last = current
+		onChange()
+		// files of reloaded config may differ, then they are taken as new base,
+		// otherwise files changed during reload are noticed on next poll
+		if reloaded := files(); !sameFiles(watched, reloaded) {
+			last = newFingerprint(reloaded)
+		}
+	}
+}
+
+func sameFiles(files, other []string) bool {
+	if len(files) != len(other) {
+		return false
+	}
+	for i := range files {
+		if files[i] != other[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,128 @@
+package config
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFingerprint(t *testing.T) {
+	tests := []struct {
+		name    string
+		change  func(t *testing.T, dir string)
+		changed bool
+	}{
+		{name: "unchanged files", change: func(*testing.T, string) {}},
+		{
+			name: "changed file",
+			change: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, "main.yaml"), "tests: [{name: changed}]\n")
+			},
+			changed: true,
+		},
+		{
+			name: "added file of directory",
+			change: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, "tests", "b.yaml"), "tests: []\n")
+			},
+			changed: true,
+		},
+		{
+			name: "removed file",
+			change: func(t *testing.T, dir string) {
+				if err := os.Remove(filepath.Join(dir, "tests", "a.yaml")); err != nil {
+					t.Fatal(err)
+				}
+			},
+			changed: true,
+		},
+		{
+			name: "created overlay",
+			change: func(t *testing.T, dir string) {
+				writeFile(t, filepath.Join(dir, EnvsDir, "staging.yaml"), "tests: []\n")
+			},
+			changed: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, map[string]string{
+				"main.yaml":    "tests: []\n",
+				"tests/a.yaml": "tests: []\n",
+			})
+			files := []string{
+				filepath.Join(dir, "main.yaml"),
+				filepath.Join(dir, "tests", "a.yaml"),
+				filepath.Join(dir, EnvsDir, "staging.yaml"),
+			}
+			before := newFingerprint(files)
+			tt.change(t, dir)
+			if changed := !newFingerprint(files).equal(before); changed != tt.changed {
+				t.Fatalf("expected change %t, got %t", tt.changed, changed)
+			}
+		})
+	}
+}
+
+// writeFile write file with new modification time, so change is noticed on file systems with coarse time
+func writeFile(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	later := time.Now().Add(time.Second)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Dir(path)
+	if err := os.Chtimes(dir, later, later); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	const interval = 10 * time.Millisecond
+	dir := writeFiles(t, map[string]string{"main.yaml": "tests: []\n"})
+	path := filepath.Join(dir, "main.yaml")
+	files := func() []string { return []string{path} }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := make(chan struct{})
+	reloaded := make(chan struct{})
+	go Watch(ctx, interval, files, func() {
+		changes <- struct{}{}
+		<-reloaded
+	})
+
+	time.Sleep(2 * interval)
+	writeFile(t, path, "tests: [{name: a}]\n")
+	select {
+	case <-changes:
+	case <-time.After(time.Second):
+		t.Fatal("change isn't noticed")
+	}
+	// file changed during reload is noticed on next poll
+	later := time.Now().Add(2 * time.Second)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+	reloaded <- struct{}{}
+	select {
+	case <-changes:
+		reloaded <- struct{}{}
+	case <-time.After(time.Second):
+		t.Fatal("change during reload isn't noticed")
+	}
+	// unchanged files don't trigger reload
+	select {
+	case <-changes:
+		t.Fatal("reload of unchanged files")
+	case <-time.After(5 * interval):
+	}
+}
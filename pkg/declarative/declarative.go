@@ -0,0 +1,118 @@
+// Package declarative contains helpers of testers configured in yaml without Go code:
+// templates over raw params of state and extraction of returned fields from json responses
+package declarative
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/models"
+)
+
+// funcs available in templates of declarative testers
+var funcs = template.FuncMap{
+	"json": func(value interface{}) (string, error) {
+		data, err := json.Marshal(value)
+		return string(data), err
+	},
+}
+
+// ParseTemplate parse text/template, missing params of state are errors
+func ParseTemplate(name, text string) (*template.Template, error) {
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, common.ErrInvalidConfig(fmt.Sprintf("template %s: %s", name, err))
+	}
+	return tmpl, nil
+}
+
+// Render template with raw params of state
+func Render(tmpl *template.Template, params map[string]interface{}) (string, error) {
+	buf := bytes.NewBuffer(make([]byte, 0))
+	if err := tmpl.Execute(buf, params); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// Extractor of returned fields of state from json document by JSONPath
+type Extractor struct {
+	method string
+	exprs  map[string]string
+	paths  map[string]Path
+}
+
+// Fields return sorted names of returned fields
+func (e *Extractor) Fields() []string {
+	fields := make([]string, 0, len(e.paths))
+	for field := range e.paths {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
+
+// Extract values of returned fields from json document, numbers are kept as json.Number
+func (e *Extractor) Extract(data []byte) (map[string]interface{}, error) {
+	values := make(map[string]interface{}, len(e.paths))
+	if len(e.paths) == 0 {
+		return values, nil
+	}
+	var doc interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		return nil, common.ErrExtractFailed(e.method, "response", "$", err.Error())
+	}
+	for field, path := range e.paths {
+		value, err := path.Lookup(doc)
+		if err != nil {
+			return nil, common.ErrExtractFailed(e.method, field, e.exprs[field], err.Error())
+		}
+		values[field] = value
+	}
+	return values, nil
+}
+
+// NewExtractor construct extractor of method from map of returned field to JSONPath
+func NewExtractor(method string, exprs map[string]string) (*Extractor, error) {
+	e := &Extractor{method: method, exprs: exprs, paths: make(map[string]Path, len(exprs))}
+	for field, expr := range exprs {
+		path, err := ParsePath(expr)
+		if err != nil {
+			return nil, common.ErrInvalidConfig(fmt.Sprintf("tester %s field %s: %s", method, field, err))
+		}
+		e.paths[field] = path
+	}
+	return e, nil
+}
+
+// CheckFields check that required and returned fields of tester are fields of state, otherwise typo in config
+// is found only as missing field in later stage. State without types of fields isn't checked.
+func CheckFields(method string, state models.State, required, returned []string) error {
+	typed, ok := state.(models.FieldTyped)
+	if !ok {
+		return nil
+	}
+	types := typed.FieldTypes()
+	unknown := make([]string, 0)
+	for _, fields := range [][]string{required, returned} {
+		for _, field := range fields {
+			if _, ok = types[field]; !ok {
+				unknown = append(unknown, field)
+			}
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	return common.ErrInvalidConfig(
+		fmt.Sprintf("tester %s uses unknown fields of state: %s", method, strings.Join(unknown, ", ")),
+	)
+}
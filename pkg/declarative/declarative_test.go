@@ -0,0 +1,105 @@
+package declarative
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/lueurxax/e2e/pkg/models"
+)
+
+func TestRender(t *testing.T) {
+	params := map[string]interface{}{"user_id": 42, "tags": []string{"a", "b"}, "name": "bob"}
+	tests := []struct {
+		name     string
+		text     string
+		result   string
+		parseErr bool
+		err      string
+	}{
+		{name: "params", text: "/users/{{.user_id}}?name={{.name}}", result: "/users/42?name=bob"},
+		{name: "json", text: `{"tags": {{json .tags}}, "name": {{json .name}}}`, result: `{"tags": ["a","b"], "name": "bob"}`},
+		{name: "missing param", text: "/users/{{.account_id}}", err: "account_id"},
+		{name: "invalid template", text: "/users/{{.user_id", parseErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParseTemplate("tester.url", tt.text)
+			if tt.parseErr {
+				if err == nil || !strings.Contains(err.Error(), "template tester.url") {
+					t.Fatalf("expected error of template, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := Render(tmpl, params)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil || result != tt.result {
+				t.Fatalf("expected %q, got %q (%v)", tt.result, result, err)
+			}
+		})
+	}
+}
+
+func TestExtractor(t *testing.T) {
+	extractor, err := NewExtractor("GetUser", map[string]string{
+		"user_id": "$.data.items[0].id",
+		"name":    "$.data.items[0].name",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fields := extractor.Fields(); strings.Join(fields, ",") != "name,user_id" {
+		t.Fatalf("unexpected fields %v", fields)
+	}
+	values, err := extractor.Extract([]byte(document))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["user_id"] != json.Number("1") || values["name"] != "first" {
+		t.Fatalf("unexpected values %v", values)
+	}
+
+	_, err = extractor.Extract([]byte(`{"data": {"items": []}}`))
+	if err == nil || !strings.Contains(err.Error(), "method GetUser cannot extract field") ||
+		!strings.Contains(err.Error(), "out of range") {
+		t.Fatalf("expected error of extraction, got %v", err)
+	}
+	if _, err = extractor.Extract([]byte("not json")); err == nil {
+		t.Fatal("expected error of invalid json")
+	}
+	if _, err = NewExtractor("GetUser", map[string]string{"user_id": "data.id"}); err == nil {
+		t.Fatal("expected error of invalid path")
+	}
+}
+
+// typedState state with types of fields
+type typedState struct {
+	models.State
+}
+
+func (s *typedState) FieldTypes() map[string]string {
+	return map[string]string{"user_id": "int", "name": "string"}
+}
+
+func TestCheckFields(t *testing.T) {
+	state := &typedState{}
+	if err := CheckFields("GetUser", state, []string{"user_id"}, []string{"name"}); err != nil {
+		t.Fatal(err)
+	}
+	err := CheckFields("GetUser", state, []string{"user_id", "usr_id"}, []string{"nmae"})
+	if err == nil || !strings.Contains(err.Error(), "tester GetUser uses unknown fields of state: nmae, usr_id") {
+		t.Fatalf("expected error of unknown fields, got %v", err)
+	}
+	// state without types of fields isn't checked
+	if err = CheckFields("GetUser", state.State, []string{"usr_id"}, nil); err != nil {
+		t.Fatal(err)
+	}
+}
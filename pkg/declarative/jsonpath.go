@@ -0,0 +1,86 @@
+package declarative
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// pathStep one step of JSONPath, key of object or index of array
+type pathStep struct {
+	key     string
+	index   int
+	isIndex bool
+}
+
+// Path subset of JSONPath with root, dot keys, bracket keys and array indexes: $.data['items'][0].id
+type Path []pathStep
+
+// ParsePath parse JSONPath expression
+func ParsePath(expr string) (Path, error) {
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("path %s must start with $", expr)
+	}
+	path := make(Path, 0)
+	rest := expr[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("path %s has empty key", expr)
+			}
+			path = append(path, pathStep{key: rest[:end]})
+			rest = rest[end:]
+		case '[':
+			end := strings.IndexByte(rest, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("path %s has unclosed bracket", expr)
+			}
+			inner := rest[1:end]
+			rest = rest[end+1:]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				path = append(path, pathStep{key: inner[1 : len(inner)-1]})
+				continue
+			}
+			index, err := strconv.Atoi(inner)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("path %s has invalid index %s", expr, inner)
+			}
+			path = append(path, pathStep{index: index, isIndex: true})
+		default:
+			return nil, fmt.Errorf("path %s has unexpected symbol %q", expr, rest[0])
+		}
+	}
+	return path, nil
+}
+
+// Lookup value of decoded json document
+func (p Path) Lookup(doc interface{}) (interface{}, error) {
+	value := doc
+	for _, step := range p {
+		if step.isIndex {
+			array, ok := value.([]interface{})
+			if !ok {
+				return nil, fmt.Errorf("value is not array for index %d", step.index)
+			}
+			if step.index >= len(array) {
+				return nil, fmt.Errorf("index %d out of range %d", step.index, len(array))
+			}
+			value = array[step.index]
+			continue
+		}
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("value is not object for key %s", step.key)
+		}
+		if value, ok = object[step.key]; !ok {
+			return nil, fmt.Errorf("key %s not found", step.key)
+		}
+	}
+	return value, nil
+}
@@ -0,0 +1,108 @@
+package declarative
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const document = `{
+	"data": {
+		"items": [{"id": 1, "name": "first"}, {"id": 2, "tags": ["a", "b"]}],
+		"key with spaces": "spaced",
+		"dotted.key": true
+	}
+}`
+
+func decode(t *testing.T, data string) interface{} {
+	t.Helper()
+	var doc interface{}
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&doc); err != nil {
+		t.Fatal(err)
+	}
+	return doc
+}
+
+func TestParsePath(t *testing.T) {
+	tests := []struct {
+		expr string
+		path Path
+		err  string
+	}{
+		{expr: "$", path: Path{}},
+		{expr: "$.data.items", path: Path{{key: "data"}, {key: "items"}}},
+		{expr: "$.data['items'][0].id", path: Path{{key: "data"}, {key: "items"}, {index: 0, isIndex: true}, {key: "id"}}},
+		{expr: `$["key with spaces"]`, path: Path{{key: "key with spaces"}}},
+		{expr: "$[10]", path: Path{{index: 10, isIndex: true}}},
+		{expr: "data.items", err: "must start with $"},
+		{expr: "$..items", err: "empty key"},
+		{expr: "$.items[0", err: "unclosed bracket"},
+		{expr: "$.items[-1]", err: "invalid index -1"},
+		{expr: "$.items[first]", err: "invalid index first"},
+		{expr: "$items", err: "unexpected symbol"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			path, err := ParsePath(tt.expr)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(path) != len(tt.path) {
+				t.Fatalf("expected %+v, got %+v", tt.path, path)
+			}
+			for i := range path {
+				if path[i] != tt.path[i] {
+					t.Fatalf("expected %+v, got %+v", tt.path, path)
+				}
+			}
+		})
+	}
+}
+
+func TestPathLookup(t *testing.T) {
+	doc := decode(t, document)
+	tests := []struct {
+		expr  string
+		value interface{}
+		err   string
+	}{
+		{expr: "$.data.items[0].id", value: json.Number("1")},
+		{expr: "$.data.items[1].tags[1]", value: "b"},
+		{expr: "$['data']['key with spaces']", value: "spaced"},
+		{expr: `$.data["dotted.key"]`, value: true},
+		{expr: "$.data.items[2].id", err: "index 2 out of range 2"},
+		{expr: "$.data.items[1].tags[5]", err: "index 5 out of range 2"},
+		{expr: "$.data.missing", err: "key missing not found"},
+		{expr: "$.data.items.id", err: "value is not object for key id"},
+		{expr: "$.data[0]", err: "value is not array for index 0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			path, err := ParsePath(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			value, err := path.Lookup(doc)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if value != tt.value {
+				t.Fatalf("expected %v, got %v", tt.value, value)
+			}
+		})
+	}
+}
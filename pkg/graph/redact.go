@@ -0,0 +1,24 @@
+package graph
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+
+	"github.com/lueurxax/e2e/pkg/secrets"
+)
+
+// RedactSecrets response middleware, it redacts secrets of config in data and errors of responses
+func RedactSecrets(ctx context.Context, next graphql.ResponseHandler) *graphql.Response {
+	resp := next(ctx)
+	if resp == nil {
+		return nil
+	}
+	if len(resp.Data) > 0 {
+		resp.Data = secrets.RedactJSON(resp.Data)
+	}
+	for _, err := range resp.Errors {
+		err.Message = secrets.Redact(err.Message)
+	}
+	return resp
+}
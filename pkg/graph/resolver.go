@@ -1,6 +1,7 @@
 package graph
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/lueurxax/e2e/pkg/log"
@@ -13,6 +14,9 @@ import (
 //
 // It serves as dependency injection for your app, add any dependencies you require here.
 
+// progressBuffer size of buffer for stress progress updates of one subscriber
+const progressBuffer = 16
+
 // Resolver struct, root of resolvers
 type Resolver struct {
 	logger  log.Logger
@@ -26,8 +30,14 @@ type manager interface {
 	CurrentLaunch() (info *models.LaunchInfo, err error)
 	RunAllTests() (err error)
 	RunTests(names []string) (err error)
+	RunAdHocScenario(data []byte) (launchID string, err error)
 	CompletedScenarios() []models.CompletedTest
 	SubscribeOnCompletedTests(chan<- *models.CompletedTest)
+	SubscribeOnStressProgress(ctx context.Context, ch chan<- *models.StressProgress)
+	Checkpoints(launchID string) (checkpoints []models.Checkpoint, err error)
+	ReloadConfig() (reload *models.ConfigReload, err error)
+	Plan(names []string) (plans []models.ScenarioPlan, err error)
+	Testers() (testers []models.TesterMeta)
 }
 
 // NewResolver construct new resolver
@@ -24,6 +24,14 @@ func (r *mutationResolver) RunTest(ctx context.Context, scenarios []string) (boo
 	return err == nil, err
 }
 
+func (r *mutationResolver) ReloadConfig(ctx context.Context) (*models.ConfigReload, error) {
+	return r.manager.ReloadConfig()
+}
+
+func (r *mutationResolver) RunAdHocScenario(ctx context.Context, yaml string) (string, error) {
+	return r.manager.RunAdHocScenario([]byte(yaml))
+}
+
 func (r *queryResolver) AvailableScenarios(ctx context.Context) ([]string, error) {
 	scenarios := r.manager.AllScenarios()
 	tests := make([]string, len(scenarios))
@@ -51,12 +59,74 @@ func (r *queryResolver) LastReport(ctx context.Context) (*string, error) {
 	return &link, nil
 }
 
+func (r *queryResolver) Checkpoints(ctx context.Context, launchID *string) ([]*models.Checkpoint, error) {
+	var id string
+	if launchID != nil {
+		id = *launchID
+	}
+	data, err := r.manager.Checkpoints(id)
+	if err != nil {
+		return nil, err
+	}
+	checkpoints := make([]*models.Checkpoint, len(data))
+	for i := range data {
+		checkpoints[i] = &data[i]
+	}
+	return checkpoints, nil
+}
+
+func (r *queryResolver) Plan(ctx context.Context, scenarios []string) ([]*models.ScenarioPlan, error) {
+	data, err := r.manager.Plan(scenarios)
+	if err != nil {
+		return nil, err
+	}
+	plans := make([]*models.ScenarioPlan, len(data))
+	for i := range data {
+		plans[i] = &data[i]
+	}
+	return plans, nil
+}
+
+func (r *queryResolver) Testers(ctx context.Context) ([]*models.TesterMeta, error) {
+	data := r.manager.Testers()
+	testers := make([]*models.TesterMeta, len(data))
+	for i := range data {
+		testers[i] = &data[i]
+	}
+	return testers, nil
+}
+
 func (r *subscriptionResolver) CurrentLaunchInfo(ctx context.Context) (<-chan *models.CompletedTest, error) {
 	ch := make(chan *models.CompletedTest)
 	go r.manager.SubscribeOnCompletedTests(ch)
 	return ch, nil
 }
 
+func (r *subscriptionResolver) StressProgress(ctx context.Context, launchID string) (<-chan *models.StressProgress, error) {
+	updates := make(chan *models.StressProgress, progressBuffer)
+	ch := make(chan *models.StressProgress)
+	r.manager.SubscribeOnStressProgress(ctx, updates)
+	go func() {
+		defer close(ch)
+		for {
+			select {
+			case progress := <-updates:
+				if progress.LaunchID != launchID {
+					continue
+				}
+				select {
+				case ch <- progress:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch, nil
+}
+
 // CompletedTest returns generated.CompletedTestResolver implementation.
 func (r *Resolver) CompletedTest() generated.CompletedTestResolver { return &completedTestResolver{r} }
 
@@ -0,0 +1,121 @@
+package grpctester
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/bufbuild/protocompile"
+	"google.golang.org/grpc"
+	rpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/lueurxax/e2e/common"
+)
+
+// descriptorResolver find descriptors by full name, implemented by protoregistry.Files and compiled proto files
+type descriptorResolver interface {
+	FindDescriptorByName(name protoreflect.FullName) (protoreflect.Descriptor, error)
+}
+
+// compileProtoFiles parse and link proto files, well known types are available for import
+func compileProtoFiles(files, importPaths []string) (descriptorResolver, error) {
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{ImportPaths: importPaths}),
+	}
+	compiled, err := compiler.Compile(context.Background(), files...)
+	if err != nil {
+		return nil, err
+	}
+	return compiled.AsResolver(), nil
+}
+
+// loadDescriptorSet read serialized FileDescriptorSet, like output of protoc --descriptor_set_out --include_imports
+func loadDescriptorSet(path string) (descriptorResolver, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	set := &descriptorpb.FileDescriptorSet{}
+	if err = proto.Unmarshal(data, set); err != nil {
+		return nil, err
+	}
+	return protodesc.NewFiles(set)
+}
+
+// reflectService fetch file of service with all dependencies from server reflection
+func reflectService(ctx context.Context, conn *grpc.ClientConn, service string) (descriptorResolver, error) {
+	stream, err := rpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = stream.CloseSend() }()
+
+	files := map[string]*descriptorpb.FileDescriptorProto{}
+	request := &rpb.ServerReflectionRequest{
+		MessageRequest: &rpb.ServerReflectionRequest_FileContainingSymbol{FileContainingSymbol: service},
+	}
+	for request != nil {
+		if err = stream.Send(request); err != nil {
+			return nil, err
+		}
+		var resp *rpb.ServerReflectionResponse
+		if resp, err = stream.Recv(); err != nil {
+			return nil, err
+		}
+		if e := resp.GetErrorResponse(); e != nil {
+			return nil, common.ErrInvalidConfig(fmt.Sprintf("reflection of %s: %s", service, e.GetErrorMessage()))
+		}
+		for _, data := range resp.GetFileDescriptorResponse().GetFileDescriptorProto() {
+			file := &descriptorpb.FileDescriptorProto{}
+			if err = proto.Unmarshal(data, file); err != nil {
+				return nil, err
+			}
+			files[file.GetName()] = file
+		}
+		request = nextMissingDependency(files)
+	}
+
+	set := &descriptorpb.FileDescriptorSet{File: make([]*descriptorpb.FileDescriptorProto, 0, len(files))}
+	for _, file := range files {
+		set.File = append(set.File, file)
+	}
+	return protodesc.NewFiles(set)
+}
+
+// nextMissingDependency return request of file imported by fetched files, but not fetched yet
+func nextMissingDependency(files map[string]*descriptorpb.FileDescriptorProto) *rpb.ServerReflectionRequest {
+	for _, file := range files {
+		for _, dependency := range file.GetDependency() {
+			if _, ok := files[dependency]; !ok {
+				return &rpb.ServerReflectionRequest{
+					MessageRequest: &rpb.ServerReflectionRequest_FileByFilename{FileByFilename: dependency},
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// findMethod find descriptor of method of service
+func findMethod(resolver descriptorResolver, service, method string) (protoreflect.MethodDescriptor, error) {
+	descriptor, err := resolver.FindDescriptorByName(protoreflect.FullName(service))
+	if err != nil {
+		return nil, common.ErrInvalidConfig(fmt.Sprintf("service %s not found: %s", service, err))
+	}
+	serviceDescriptor, ok := descriptor.(protoreflect.ServiceDescriptor)
+	if !ok {
+		return nil, common.ErrInvalidConfig(fmt.Sprintf("%s is not service", service))
+	}
+	methodDescriptor := serviceDescriptor.Methods().ByName(protoreflect.Name(method))
+	if methodDescriptor == nil {
+		return nil, common.ErrInvalidConfig(fmt.Sprintf("method %s not found in service %s", method, service))
+	}
+	if methodDescriptor.IsStreamingClient() || methodDescriptor.IsStreamingServer() {
+		return nil, common.ErrInvalidConfig(fmt.Sprintf("streaming method %s/%s isn't supported", service, method))
+	}
+	return methodDescriptor, nil
+}
@@ -0,0 +1,235 @@
+package grpctester
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/dynamicpb"
+
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/clientfactory"
+	"github.com/lueurxax/e2e/pkg/declarative"
+	"github.com/lueurxax/e2e/pkg/models"
+)
+
+// marshalResponse fields of response are named as in proto files and zero values are kept for extraction
+var marshalResponse = protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}
+
+// tester declarative grpc tester, it builds dynamic messages from descriptors,
+// so it needs neither generated grpc code nor generated methods of state
+type tester struct {
+	conf     common.GRPCTester
+	state    models.RawState
+	clients  *clientfactory.Set
+	service  string
+	name     string
+	expected codes.Code
+
+	request  *template.Template
+	metadata map[string]*template.Template
+	extract  *declarative.Extractor
+
+	// mu guards method, with reflection it is resolved on first call
+	mu     sync.Mutex
+	method protoreflect.MethodDescriptor
+}
+
+func (t *tester) MethodName() (name string) {
+	return t.conf.Name
+}
+
+func (t *tester) RequiredFields() (fields []string) {
+	return t.conf.Required
+}
+
+func (t *tester) ReturnedFields() (fields []string) {
+	return t.extract.Fields()
+}
+
+func (t *tester) Description() string {
+	return t.conf.GetDescription()
+}
+
+func (t *tester) ClientKinds() []string {
+	return []string{common.ClientGRPC}
+}
+
+// Run render request from selected state, call method, check code and extract returned fields from response
+func (t *tester) Run(
+	ctx context.Context,
+	client string,
+	selector models.StateSelector,
+	opts *models.Options,
+) (models.StateSelector, error) {
+	conn, err := clientfactory.Get[*grpc.ClientConn](t.clients, client)
+	if err != nil {
+		return nil, err
+	}
+	method, err := t.resolve(ctx, conn)
+	if err != nil {
+		return nil, err
+	}
+	params := t.state.Raw(selector)
+	req, err := t.newRequest(method, params)
+	if err != nil {
+		return nil, err
+	}
+	if ctx, err = t.outgoingContext(ctx, params); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, t.conf.GetTimeout())
+	defer cancel()
+
+	resp := dynamicpb.NewMessage(method.Output())
+	err = conn.Invoke(ctx, fmt.Sprintf("/%s/%s", t.service, t.name), req, resp)
+	if code := status.Code(err); code != t.expected {
+		return nil, common.ErrUnexpectedCode(t.conf.Name, code.String(), status.Convert(err).Message())
+	}
+	if err != nil {
+		// expected error has no response to extract fields from
+		return t.state.NewRaw(selector, nil)
+	}
+	data, err := marshalResponse.Marshal(resp)
+	if err != nil {
+		return nil, err
+	}
+	values, err := t.extract.Extract(data)
+	if err != nil {
+		return nil, err
+	}
+	return t.state.NewRaw(selector, values)
+}
+
+// resolve descriptor of method, descriptors of server reflection are fetched once
+func (t *tester) resolve(ctx context.Context, conn *grpc.ClientConn) (protoreflect.MethodDescriptor, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.method != nil {
+		return t.method, nil
+	}
+	resolver, err := reflectService(ctx, conn, t.service)
+	if err != nil {
+		return nil, err
+	}
+	if t.method, err = findMethod(resolver, t.service, t.name); err != nil {
+		return nil, err
+	}
+	return t.method, nil
+}
+
+func (t *tester) newRequest(method protoreflect.MethodDescriptor, params map[string]interface{}) (*dynamicpb.Message, error) {
+	body, err := declarative.Render(t.request, params)
+	if err != nil {
+		return nil, err
+	}
+	req := dynamicpb.NewMessage(method.Input())
+	if err = protojson.Unmarshal([]byte(body), req); err != nil {
+		return nil, common.ErrInvalidConfig(fmt.Sprintf("request of grpc tester %s: %s", t.conf.Name, err))
+	}
+	return req, nil
+}
+
+func (t *tester) outgoingContext(ctx context.Context, params map[string]interface{}) (context.Context, error) {
+	if len(t.metadata) == 0 {
+		return ctx, nil
+	}
+	md := metadata.MD{}
+	for key, tmpl := range t.metadata {
+		value, err := declarative.Render(tmpl, params)
+		if err != nil {
+			return nil, err
+		}
+		md.Set(key, value)
+	}
+	return metadata.NewOutgoingContext(ctx, md), nil
+}
+
+// parseCode parse name of grpc code like NotFound or NOT_FOUND, OK by default
+func parseCode(name string) (codes.Code, error) {
+	if name == "" {
+		return codes.OK, nil
+	}
+	normalized := strings.ReplaceAll(strings.ToLower(name), "_", "")
+	for code := codes.OK; code <= codes.Unauthenticated; code++ {
+		if strings.ToLower(code.String()) == normalized {
+			return code, nil
+		}
+	}
+	return codes.OK, common.ErrInvalidConfig(fmt.Sprintf("unknown grpc code %s", name))
+}
+
+// New construct declarative grpc tester, state must implement models.RawState, tester uses clients of grpc kind
+func New(conf common.GRPCTester, state models.State, clients *clientfactory.Set) (models.Tester, error) {
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+	raw, ok := state.(models.RawState)
+	if !ok {
+		return nil, common.ErrInvalidConfig("state doesn't implement raw state")
+	}
+	t := &tester{
+		conf:     conf,
+		state:    raw,
+		clients:  clients,
+		metadata: make(map[string]*template.Template, len(conf.Metadata)),
+	}
+	t.service, t.name = conf.Service()
+	var err error
+	if t.expected, err = parseCode(conf.ExpectedCode); err != nil {
+		return nil, err
+	}
+	if t.request, err = declarative.ParseTemplate(conf.Name+".request", conf.GetRequest()); err != nil {
+		return nil, err
+	}
+	for key, value := range conf.Metadata {
+		if t.metadata[key], err = declarative.ParseTemplate(conf.Name+".metadata."+key, value); err != nil {
+			return nil, err
+		}
+	}
+	if t.extract, err = declarative.NewExtractor(conf.Name, conf.Extract); err != nil {
+		return nil, err
+	}
+	if err = declarative.CheckFields(conf.Name, state, conf.Required, t.extract.Fields()); err != nil {
+		return nil, err
+	}
+
+	// descriptors of files are resolved at start, server reflection requires connection, so it is lazy
+	var resolver descriptorResolver
+	switch {
+	case len(conf.ProtoFiles) > 0:
+		resolver, err = compileProtoFiles(conf.ProtoFiles, conf.ImportPaths)
+	case conf.DescriptorSet != "":
+		resolver, err = loadDescriptorSet(conf.DescriptorSet)
+	}
+	if err != nil {
+		return nil, common.ErrInvalidConfig(fmt.Sprintf("descriptors of grpc tester %s: %s", conf.Name, err))
+	}
+	if resolver != nil {
+		if t.method, err = findMethod(resolver, t.service, t.name); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// NewTesters construct declarative grpc testers for testers pool
+func NewTesters(confs []common.GRPCTester, state models.State, clients *clientfactory.Set) ([]models.Tester, error) {
+	testers := make([]models.Tester, len(confs))
+	for i, conf := range confs {
+		tester, err := New(conf, state, clients)
+		if err != nil {
+			return nil, err
+		}
+		testers[i] = tester
+	}
+	return testers, nil
+}
@@ -0,0 +1,261 @@
+package grpctester
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/clientfactory"
+	"github.com/lueurxax/e2e/pkg/models"
+)
+
+const method = "grpc.health.v1.Health/Check"
+
+type selector int
+
+func (s selector) Index() int {
+	return int(s)
+}
+
+// rawState state of raw params, results of runs are appended
+type rawState struct {
+	models.State
+	params []map[string]interface{}
+}
+
+func (s *rawState) add(params map[string]interface{}) models.StateSelector {
+	s.params = append(s.params, params)
+	return selector(len(s.params) - 1)
+}
+
+func (s *rawState) Raw(sel models.StateSelector) map[string]interface{} {
+	return s.params[sel.Index()]
+}
+
+func (s *rawState) NewRaw(_ models.StateSelector, values map[string]interface{}) (models.StateSelector, error) {
+	return s.add(values), nil
+}
+
+// newServer serve health service with reflection, calls without user in metadata are rejected
+func newServer(t *testing.T) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer(grpc.UnaryInterceptor(func(
+		ctx context.Context,
+		req interface{},
+		info *grpc.UnaryServerInfo,
+		handler grpc.UnaryHandler,
+	) (interface{}, error) {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if users := md.Get("x-user"); len(users) == 0 || users[0] != "bob" {
+			return nil, status.Error(codes.Unauthenticated, "unknown user")
+		}
+		return handler(ctx, req)
+	}))
+	checker := health.NewServer()
+	checker.SetServingStatus("users", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(srv, checker)
+	reflection.Register(srv)
+	go func() { _ = srv.Serve(listener) }()
+	t.Cleanup(srv.Stop)
+	return listener.Addr().String()
+}
+
+// writeDescriptorSet write descriptor set of health service to temporary file
+func writeDescriptorSet(t *testing.T) string {
+	t.Helper()
+	set := &descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{
+		protodesc.ToFileDescriptorProto(healthpb.File_grpc_health_v1_health_proto),
+	}}
+	data, err := proto.Marshal(set)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "health.pb")
+	if err = os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestTesterRun(t *testing.T) {
+	clients, err := clientfactory.Build([]common.Client{
+		{Name: "health", Type: common.ClientGRPC, Url: "grpc://" + newServer(t)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	descriptorSet := writeDescriptorSet(t)
+	sources := []struct {
+		name string
+		conf common.GRPCTester
+	}{
+		{name: "proto files", conf: common.GRPCTester{ProtoFiles: []string{"testdata/health/health.proto"}}},
+		{
+			name: "proto files of import paths",
+			conf: common.GRPCTester{ProtoFiles: []string{"health/health.proto"}, ImportPaths: []string{"testdata"}},
+		},
+		{name: "descriptor set", conf: common.GRPCTester{DescriptorSet: descriptorSet}},
+		{name: "reflection", conf: common.GRPCTester{Reflection: true}},
+	}
+	params := map[string]interface{}{"service": "users", "user": "bob"}
+	tests := []struct {
+		name   string
+		conf   common.GRPCTester
+		params map[string]interface{}
+		values map[string]interface{}
+		err    string
+	}{
+		{
+			name: "templates of request",
+			conf: common.GRPCTester{
+				Request:  `{"service": {{json .service}}}`,
+				Metadata: map[string]string{"x-user": "{{.user}}"},
+				Extract:  map[string]string{"status": "$.status"},
+			},
+			values: map[string]interface{}{"status": "SERVING"},
+		},
+		{
+			name: "expected code",
+			conf: common.GRPCTester{
+				Request:      `{"service": "orders"}`,
+				Metadata:     map[string]string{"x-user": "{{.user}}"},
+				ExpectedCode: "NOT_FOUND",
+			},
+			values: map[string]interface{}{},
+		},
+		{
+			name:   "unexpected code",
+			conf:   common.GRPCTester{Request: `{"service": {{json .service}}}`, Metadata: map[string]string{"x-user": "{{.user}}"}},
+			params: map[string]interface{}{"service": "users", "user": "alice"},
+			err:    "method Test got unexpected code Unauthenticated: unknown user",
+		},
+		{
+			name: "missing param of template",
+			conf: common.GRPCTester{Request: `{"service": {{json .account}}}`},
+			err:  "account",
+		},
+		{
+			name: "request of unknown field",
+			conf: common.GRPCTester{Request: `{"name": {{json .service}}}`},
+			err:  "request of grpc tester Test",
+		},
+		{
+			name: "failed extraction",
+			conf: common.GRPCTester{
+				Request:  `{"service": {{json .service}}}`,
+				Metadata: map[string]string{"x-user": "{{.user}}"},
+				Extract:  map[string]string{"status": "$.state"},
+			},
+			err: "method Test cannot extract field status by $.state: key state not found",
+		},
+	}
+	for _, source := range sources {
+		for _, tt := range tests {
+			t.Run(source.name+"/"+tt.name, func(t *testing.T) {
+				state := &rawState{}
+				conf := tt.conf
+				conf.Name = "Test"
+				conf.Method = method
+				conf.ProtoFiles = source.conf.ProtoFiles
+				conf.ImportPaths = source.conf.ImportPaths
+				conf.DescriptorSet = source.conf.DescriptorSet
+				conf.Reflection = source.conf.Reflection
+				tester, err := New(conf, state, clients)
+				if err != nil {
+					t.Fatal(err)
+				}
+				runParams := params
+				if tt.params != nil {
+					runParams = tt.params
+				}
+				result, err := tester.Run(context.Background(), "health", state.add(runParams), &models.Options{})
+				if tt.err != "" {
+					if err == nil || !strings.Contains(err.Error(), tt.err) {
+						t.Fatalf("expected error %q, got %v", tt.err, err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				values := state.Raw(result)
+				if len(values) != len(tt.values) {
+					t.Fatalf("expected %v, got %v", tt.values, values)
+				}
+				for key, value := range tt.values {
+					if values[key] != value {
+						t.Fatalf("expected %s %v, got %v", key, value, values[key])
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestNewTester(t *testing.T) {
+	tests := []struct {
+		name string
+		conf common.GRPCTester
+		err  string
+	}{
+		{
+			name: "without source of descriptors",
+			conf: common.GRPCTester{Method: method},
+			err:  "requires exactly one of proto_files, descriptor_set or reflection",
+		},
+		{
+			name: "missing proto file",
+			conf: common.GRPCTester{Method: method, ProtoFiles: []string{"testdata/missing.proto"}},
+			err:  "descriptors of grpc tester Test",
+		},
+		{
+			name: "unknown method",
+			conf: common.GRPCTester{Method: "grpc.health.v1.Health/Ping", ProtoFiles: []string{"testdata/health/health.proto"}},
+			err:  "method Ping not found in service grpc.health.v1.Health",
+		},
+		{
+			name: "unknown service",
+			conf: common.GRPCTester{Method: "grpc.health.v2.Health/Check", ProtoFiles: []string{"testdata/health/health.proto"}},
+			err:  "service grpc.health.v2.Health not found",
+		},
+		{
+			name: "unknown code",
+			conf: common.GRPCTester{Method: method, Reflection: true, ExpectedCode: "Missing"},
+			err:  "unknown grpc code Missing",
+		},
+		{
+			name: "invalid template",
+			conf: common.GRPCTester{Method: method, Reflection: true, Request: `{"service": {{.service}`},
+			err:  "template Test.request",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := tt.conf
+			conf.Name = "Test"
+			_, err := New(conf, &rawState{}, nil)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("expected error %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package httptester
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"text/template"
+
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/clientfactory"
+	"github.com/lueurxax/e2e/pkg/declarative"
+	"github.com/lueurxax/e2e/pkg/models"
+)
+
+// maxErrorBody count of bytes of response body in error of unexpected status
+const maxErrorBody = 256
+
+// tester declarative http tester, it works with raw params of state, so it doesn't need generated methods
+type tester struct {
+	conf    common.HTTPTester
+	state   models.RawState
+	clients *clientfactory.Set
+
+	url     *template.Template
+	body    *template.Template
+	headers map[string]*template.Template
+	extract *declarative.Extractor
+}
+
+func (t *tester) MethodName() (name string) {
+	return t.conf.Name
+}
+
+func (t *tester) RequiredFields() (fields []string) {
+	return t.conf.Required
+}
+
+func (t *tester) ReturnedFields() (fields []string) {
+	return t.extract.Fields()
+}
+
+func (t *tester) Description() string {
+	return t.conf.GetDescription()
+}
+
+func (t *tester) ClientKinds() []string {
+	return []string{common.ClientHTTP}
+}
+
+// Run render request from selected state, check status and extract returned fields from json response
+func (t *tester) Run(
+	ctx context.Context,
+	client string,
+	selector models.StateSelector,
+	opts *models.Options,
+) (models.StateSelector, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.conf.GetTimeout())
+	defer cancel()
+	httpClient, req, err := t.newRequest(ctx, client, t.state.Raw(selector))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if !t.conf.CheckStatus(resp.StatusCode) {
+		if len(data) > maxErrorBody {
+			data = data[:maxErrorBody]
+		}
+		return nil, common.ErrUnexpectedStatus(t.conf.Name, resp.StatusCode, string(data))
+	}
+	values, err := t.extract.Extract(data)
+	if err != nil {
+		return nil, err
+	}
+	return t.state.NewRaw(selector, values)
+}
+
+// newRequest render request and choose client for it, absolute url may be requested without configured client
+func (t *tester) newRequest(
+	ctx context.Context,
+	client string,
+	params map[string]interface{},
+) (*http.Client, *http.Request, error) {
+	url, err := declarative.Render(t.url, params)
+	if err != nil {
+		return nil, nil, err
+	}
+	httpClient := http.DefaultClient
+	configured, err := clientfactory.Get[*clientfactory.HTTPClient](t.clients, client)
+	switch {
+	case err == nil:
+		httpClient, url = configured.Client, configured.Resolve(url)
+	case !strings.Contains(url, "://"):
+		return nil, nil, common.ErrInvalidConfig(fmt.Sprintf("client of http tester %s: %s", t.conf.Name, err))
+	}
+	var body io.Reader
+	if t.body != nil {
+		var data string
+		if data, err = declarative.Render(t.body, params); err != nil {
+			return nil, nil, err
+		}
+		body = strings.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, t.conf.GetMethod(), url, body)
+	if err != nil {
+		return nil, nil, err
+	}
+	for name, header := range t.headers {
+		var value string
+		if value, err = declarative.Render(header, params); err != nil {
+			return nil, nil, err
+		}
+		req.Header.Set(name, value)
+	}
+	return httpClient, req, nil
+}
+
+// New construct declarative http tester, state must implement models.RawState, tester uses clients of http kind
+func New(conf common.HTTPTester, state models.State, clients *clientfactory.Set) (models.Tester, error) {
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+	raw, ok := state.(models.RawState)
+	if !ok {
+		return nil, common.ErrInvalidConfig("state doesn't implement raw state")
+	}
+	t := &tester{
+		conf:    conf,
+		state:   raw,
+		clients: clients,
+		headers: make(map[string]*template.Template, len(conf.Headers)),
+	}
+	var err error
+	if t.url, err = declarative.ParseTemplate(conf.Name+".url", conf.URL); err != nil {
+		return nil, err
+	}
+	if conf.Body != "" {
+		if t.body, err = declarative.ParseTemplate(conf.Name+".body", conf.Body); err != nil {
+			return nil, err
+		}
+	}
+	for name, value := range conf.Headers {
+		if t.headers[name], err = declarative.ParseTemplate(conf.Name+".headers."+name, value); err != nil {
+			return nil, err
+		}
+	}
+	if t.extract, err = declarative.NewExtractor(conf.Name, conf.Extract); err != nil {
+		return nil, err
+	}
+	if err = declarative.CheckFields(conf.Name, state, conf.Required, t.extract.Fields()); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// NewTesters construct declarative http testers for testers pool
+func NewTesters(confs []common.HTTPTester, state models.State, clients *clientfactory.Set) ([]models.Tester, error) {
+	testers := make([]models.Tester, len(confs))
+	for i, conf := range confs {
+		tester, err := New(conf, state, clients)
+		if err != nil {
+			return nil, err
+		}
+		testers[i] = tester
+	}
+	return testers, nil
+}
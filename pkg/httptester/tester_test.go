@@ -0,0 +1,225 @@
+package httptester
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/clientfactory"
+	"github.com/lueurxax/e2e/pkg/models"
+)
+
+type selector int
+
+func (s selector) Index() int {
+	return int(s)
+}
+
+// rawState state of raw params, results of runs are appended
+type rawState struct {
+	models.State
+	params []map[string]interface{}
+}
+
+func (s *rawState) add(params map[string]interface{}) models.StateSelector {
+	s.params = append(s.params, params)
+	return selector(len(s.params) - 1)
+}
+
+func (s *rawState) Raw(sel models.StateSelector) map[string]interface{} {
+	return s.params[sel.Index()]
+}
+
+func (s *rawState) NewRaw(_ models.StateSelector, values map[string]interface{}) (models.StateSelector, error) {
+	return s.add(values), nil
+}
+
+// typedState raw state with types of fields
+type typedState struct {
+	rawState
+}
+
+func (s *typedState) FieldTypes() map[string]string {
+	return map[string]string{"user_id": "int", "token": "string"}
+}
+
+// newServer serve users api, it echoes request in response
+func newServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users/42", func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{
+				"method": r.Method,
+				"header": r.Header.Get("X-Name"),
+				"body":   string(body),
+				"items":  []interface{}{map[string]interface{}{"token": "abc"}},
+			},
+		})
+	})
+	mux.HandleFunc("/fail", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write([]byte("internal error " + strings.Repeat("x", 2*maxErrorBody)))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newClients(t *testing.T, url string) *clientfactory.Set {
+	t.Helper()
+	clients, err := clientfactory.Build([]common.Client{{Name: "api", Type: common.ClientHTTP, Url: url}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return clients
+}
+
+func TestTesterRun(t *testing.T) {
+	srv := newServer(t)
+	clients := newClients(t, srv.URL)
+	params := map[string]interface{}{"user_id": 42, "name": "bob", "tags": []string{"a", "b"}, "base": srv.URL}
+	tests := []struct {
+		name   string
+		conf   common.HTTPTester
+		client string
+		values map[string]interface{}
+		err    string
+	}{
+		{
+			name: "templates of request",
+			conf: common.HTTPTester{
+				Method:  "post",
+				URL:     "/users/{{.user_id}}",
+				Headers: map[string]string{"X-Name": "{{.name}}"},
+				Body:    `{"tags":{{json .tags}}}`,
+				Extract: map[string]string{
+					"method": "$.data.method",
+					"header": "$.data.header",
+					"body":   "$.data.body",
+					"token":  "$.data['items'][0].token",
+				},
+			},
+			client: "api",
+			values: map[string]interface{}{
+				"method": http.MethodPost, "header": "bob", "body": `{"tags":["a","b"]}`, "token": "abc",
+			},
+		},
+		{
+			name:   "absolute url without client",
+			conf:   common.HTTPTester{URL: "{{.base}}/users/{{.user_id}}", Extract: map[string]string{"method": "$.data.method"}},
+			values: map[string]interface{}{"method": http.MethodGet},
+		},
+		{
+			name: "relative url without client",
+			conf: common.HTTPTester{URL: "/users/{{.user_id}}"},
+			err:  "client of http tester Test",
+		},
+		{
+			name:   "missing param of template",
+			conf:   common.HTTPTester{URL: "/users/{{.account_id}}"},
+			client: "api",
+			err:    "account_id",
+		},
+		{
+			name:   "unexpected status",
+			conf:   common.HTTPTester{URL: "/fail"},
+			client: "api",
+			err:    "method Test got unexpected status 500: internal error",
+		},
+		{
+			name:   "expected status",
+			conf:   common.HTTPTester{URL: "/users/{{.user_id}}", ExpectedStatus: http.StatusCreated},
+			client: "api",
+			err:    "method Test got unexpected status 200",
+		},
+		{
+			name:   "failed extraction",
+			conf:   common.HTTPTester{URL: "/users/{{.user_id}}", Extract: map[string]string{"token": "$.data.items[1].token"}},
+			client: "api",
+			err:    "method Test cannot extract field token by $.data.items[1].token: index 1 out of range 1",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := &rawState{}
+			conf := tt.conf
+			conf.Name = "Test"
+			tester, err := New(conf, state, clients)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result, err := tester.Run(context.Background(), tt.client, state.add(params), &models.Options{})
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("expected error %q, got %v", tt.err, err)
+				}
+				if len(err.Error()) > len(tt.err)+maxErrorBody+64 {
+					t.Fatalf("too long error %d", len(err.Error()))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			values := state.Raw(result)
+			for key, value := range tt.values {
+				if values[key] != value {
+					t.Fatalf("expected %s %v, got %v", key, value, values[key])
+				}
+			}
+		})
+	}
+}
+
+func TestNewTester(t *testing.T) {
+	tests := []struct {
+		name string
+		conf common.HTTPTester
+		err  string
+	}{
+		{name: "without url", conf: common.HTTPTester{Name: "Test"}, err: "http tester Test without url"},
+		{name: "invalid template", conf: common.HTTPTester{Name: "Test", URL: "/{{.id"}, err: "template Test.url"},
+		{
+			name: "invalid path",
+			conf: common.HTTPTester{Name: "Test", URL: "/", Extract: map[string]string{"token": "token"}},
+			err:  "must start with $",
+		},
+		{
+			name: "unknown fields of state",
+			conf: common.HTTPTester{
+				Name: "Test", URL: "/", Required: []string{"usr_id"}, Extract: map[string]string{"tokn": "$.token"},
+			},
+			err: "tester Test uses unknown fields of state: tokn, usr_id",
+		},
+		{
+			name: "known fields of state",
+			conf: common.HTTPTester{
+				Name: "Test", URL: "/", Required: []string{"user_id"}, Extract: map[string]string{"token": "$.token"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.conf, &typedState{}, nil)
+			if tt.err == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("expected error %q, got %v", tt.err, err)
+			}
+		})
+	}
+	if _, err := New(common.HTTPTester{Name: "Test", URL: "/"}, &struct{ models.State }{}, nil); err == nil {
+		t.Fatal("expected error of state without raw params")
+	}
+}
@@ -1,10 +1,14 @@
 package log
 
 import (
+	"fmt"
+
 	"github.com/sirupsen/logrus"
+
+	"github.com/lueurxax/e2e/pkg/secrets"
 )
 
-// Logger abstruct interface for internal logging
+// Logger abstruct interface for internal logging, secrets of config are redacted in messages and fields
 type Logger interface {
 	Error(msgs ...interface{})
 	Warn(msgs ...interface{})
@@ -21,35 +25,49 @@ type logger struct {
 }
 
 func (l *logger) Warn(msgs ...interface{}) {
-	l.log.Warn(msgs...)
+	l.log.Warn(redact(msgs))
 }
 
 func (l *logger) Tracef(s string, msgs ...interface{}) {
-	l.log.Tracef(s, msgs...)
+	if !l.log.Logger.IsLevelEnabled(logrus.TraceLevel) {
+		return
+	}
+	l.log.Trace(secrets.Redact(fmt.Sprintf(s, msgs...)))
 }
 
 func (l *logger) WithField(key string, value interface{}) Logger {
-	return NewLoggerFromEntry(l.log.WithField(key, value))
+	return NewLoggerFromEntry(l.log.WithField(key, secrets.RedactValue(value)))
 }
 
 func (l *logger) WithError(err error) Logger {
-	return NewLoggerFromEntry(l.log.WithError(err))
+	return NewLoggerFromEntry(l.log.WithError(secrets.RedactError(err)))
 }
 
 func (l *logger) Error(msgs ...interface{}) {
-	l.log.Error(msgs...)
+	l.log.Error(redact(msgs))
 }
 
 func (l *logger) Info(msgs ...interface{}) {
-	l.log.Info(msgs...)
+	l.log.Info(redact(msgs))
 }
 
 func (l *logger) Debug(msgs ...interface{}) {
-	l.log.Debug(msgs...)
+	if !l.log.Logger.IsLevelEnabled(logrus.DebugLevel) {
+		return
+	}
+	l.log.Debug(redact(msgs))
 }
 
 func (l *logger) Trace(msgs ...interface{}) {
-	l.log.Trace(msgs...)
+	if !l.log.Logger.IsLevelEnabled(logrus.TraceLevel) {
+		return
+	}
+	l.log.Trace(redact(msgs))
+}
+
+// redact format messages like logrus and redact secrets
+func redact(msgs []interface{}) string {
+	return secrets.Redact(fmt.Sprint(msgs...))
 }
 
 // NewLogger construct Logger from logrus.Logger
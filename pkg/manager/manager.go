@@ -2,15 +2,20 @@ package manager
 
 import (
 	"context"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/config"
 	"github.com/lueurxax/e2e/pkg/log"
 	"github.com/lueurxax/e2e/pkg/models"
 )
 
 const (
 	taskPool = 10
+	// checkpointsBuffer size of buffer for checkpoints of soak loads
+	checkpointsBuffer = 16
 )
 
 type scenariosGetter interface {
@@ -18,7 +23,12 @@ type scenariosGetter interface {
 }
 
 type processor interface {
-	Run(ctx context.Context, scenario models.Scenario, launchID string) (err error)
+	ValidateScenario(scenario models.Scenario) (err error)
+	Plan(scenario models.Scenario) (plan *models.ScenarioPlan)
+	Testers() (testers []models.TesterMeta)
+	Run(ctx context.Context, scenario models.Scenario, launchID string) (stopReason string, err error)
+	SubscribeOnStressProgress(ctx context.Context, ch chan<- *models.StressProgress)
+	SubscribeOnCheckpoints(ctx context.Context, ch chan<- *models.Checkpoint)
 }
 
 // Manager manage test runs and history
@@ -28,14 +38,23 @@ type Manager interface {
 	CurrentLaunch() (info *models.LaunchInfo, err error)
 	RunAllTests() (err error)
 	RunTests(names []string) (err error)
+	RunAdHocScenario(data []byte) (launchID string, err error)
 	CompletedScenarios() (completed []models.CompletedTest)
 	SubscribeOnCompletedTests(ch chan<- *models.CompletedTest)
+	SubscribeOnStressProgress(ctx context.Context, ch chan<- *models.StressProgress)
+	Checkpoints(launchID string) (checkpoints []models.Checkpoint, err error)
+	ReloadConfig() (reload *models.ConfigReload, err error)
+	Plan(names []string) (plans []models.ScenarioPlan, err error)
+	Testers() (testers []models.TesterMeta)
 	Start()
 	Stop()
 }
 
 type state struct {
-	history                 []models.LaunchInfo
+	// mu guards current launch, history and listeners of completed tests, launch is updated while it is running,
+	// and scenarios with watched files, they are swapped by reload
+	mu                      sync.RWMutex
+	history                 []*models.LaunchInfo
 	running                 int32
 	scenarios               []models.Scenario
 	scenariosIndex          map[string]int
@@ -46,39 +65,107 @@ type state struct {
 	completedTasks          chan completedTask
 	currentLaunch           *models.LaunchInfo
 	listenersCompletedTests []chan<- *models.CompletedTest
+	cancel                  context.CancelFunc
+
+	// reloadMu serializes reloads of config
+	reloadMu      sync.Mutex
+	newConfig     func() ReloadableConfig
+	watchInterval time.Duration
+	files         []string
+	// clients names of clients built at start for validation of stages, nil if they are unknown
+	clients map[string]struct{}
 }
 
+// CompletedScenarios completed tests of current launch
 func (s *state) CompletedScenarios() (completed []models.CompletedTest) {
-	return s.currentLaunch.CompletedTests
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.currentLaunch == nil {
+		return nil
+	}
+	return append([]models.CompletedTest(nil), s.currentLaunch.CompletedTests...)
 }
 
 func (s *state) SubscribeOnCompletedTests(ch chan<- *models.CompletedTest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	s.listenersCompletedTests = append(s.listenersCompletedTests, ch)
 }
 
+func (s *state) SubscribeOnStressProgress(ctx context.Context, ch chan<- *models.StressProgress) {
+	s.processor.SubscribeOnStressProgress(ctx, ch)
+}
+
 func (s *state) ScenariosByNames(names []string) (scenarios []models.Scenario, err error) {
 	panic("implement me")
 }
 
+// CurrentLaunch copy of current launch, it isn't changed by running launch
 func (s *state) CurrentLaunch() (info *models.LaunchInfo, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	if s.currentLaunch != nil {
-		return s.currentLaunch, nil
+		return copyLaunch(s.currentLaunch), nil
 	}
 	return nil, common.ErrTestsDidNotRun()
 }
 
+// History copies of previous launches
 func (s *state) History() []models.LaunchInfo {
-	return s.history
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	history := make([]models.LaunchInfo, len(s.history))
+	for i, launch := range s.history {
+		history[i] = *copyLaunch(launch)
+	}
+	return history
+}
+
+// copyLaunch copy launch with its slices, caller must hold lock
+func copyLaunch(launch *models.LaunchInfo) *models.LaunchInfo {
+	info := *launch
+	info.CompletedTests = append([]models.CompletedTest(nil), launch.CompletedTests...)
+	info.Errors = append([]models.ErrorTest(nil), launch.Errors...)
+	info.Checkpoints = append([]models.Checkpoint(nil), launch.Checkpoints...)
+	return &info
+}
+
+// Checkpoints return checkpoints of soak loads of launch, current launch if id is empty
+func (s *state) Checkpoints(launchID string) (checkpoints []models.Checkpoint, err error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if launchID == "" {
+		if s.currentLaunch == nil {
+			return nil, common.ErrTestsDidNotRun()
+		}
+		launchID = s.currentLaunch.ID
+	}
+	launch := s.launch(launchID)
+	if launch == nil {
+		return nil, common.ErrUnknownLaunch(launchID)
+	}
+	return append([]models.Checkpoint(nil), launch.Checkpoints...), nil
+}
+
+// newLaunch move current launch to history and start new one
+func (s *state) newLaunch() (launchID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.currentLaunch != nil {
+		s.history = append(s.history, s.currentLaunch)
+	}
+	s.currentLaunch = models.NewLaunchInfo()
+	return s.currentLaunch.ID
 }
 
 func (s *state) RunAllTests() error {
 	if s.IsRunning() {
 		return common.ErrTestsAlreadyRunning()
 	}
-	s.currentLaunch = models.NewLaunchInfo()
+	launchID := s.newLaunch()
 	s.taskQueue <- task{
-		launchID:  s.currentLaunch.ID,
-		scenarios: s.scenarios,
+		launchID:  launchID,
+		scenarios: s.AllScenarios(),
 	}
 	return nil
 }
@@ -87,34 +174,97 @@ func (s *state) RunTests(names []string) (err error) {
 	if s.IsRunning() {
 		return common.ErrTestsAlreadyRunning()
 	}
-	s.currentLaunch = models.NewLaunchInfo()
+	launchID := s.newLaunch()
+	s.mu.RLock()
+	all, index := s.scenarios, s.scenariosIndex
+	s.mu.RUnlock()
 	scenarios := make([]models.Scenario, len(names))
 	for i, scenarioName := range names {
-		scenarioIndex, ok := s.scenariosIndex[scenarioName]
+		scenarioIndex, ok := index[scenarioName]
 		if !ok {
 			return common.ErrUnknownScenario(scenarioName)
 		}
-		scenarios[i] = s.scenarios[scenarioIndex]
+		scenarios[i] = all[scenarioIndex]
 		s.taskQueue <- task{
-			launchID:  s.currentLaunch.ID,
+			launchID:  launchID,
 			scenarios: scenarios,
 		}
 	}
 	return nil
 }
 
+// RunAdHocScenario parse scenario from yaml, validate it against testers and run it as one-off launch,
+// scenario isn't added to scenarios of config
+func (s *state) RunAdHocScenario(data []byte) (launchID string, err error) {
+	if s.IsRunning() {
+		return "", common.ErrTestsAlreadyRunning()
+	}
+	scenario, err := config.ParseScenario(data)
+	if err != nil {
+		return "", err
+	}
+	if err = s.validateScenarios([]models.Scenario{scenario}, s.clients); err != nil {
+		return "", err
+	}
+	launchID = s.newLaunch()
+	s.taskQueue <- task{
+		launchID:  launchID,
+		scenarios: []models.Scenario{scenario},
+	}
+	return launchID, nil
+}
+
+// Plan resolve execution plans of scenarios without requests, all scenarios if names are empty
+func (s *state) Plan(names []string) (plans []models.ScenarioPlan, err error) {
+	s.mu.RLock()
+	all, index := s.scenarios, s.scenariosIndex
+	s.mu.RUnlock()
+	scenarios := all
+	if len(names) > 0 {
+		scenarios = make([]models.Scenario, len(names))
+		for i, name := range names {
+			scenarioIndex, ok := index[name]
+			if !ok {
+				return nil, common.ErrUnknownScenario(name)
+			}
+			scenarios[i] = all[scenarioIndex]
+		}
+	}
+	plans = make([]models.ScenarioPlan, len(scenarios))
+	for i, scenario := range scenarios {
+		plans[i] = *s.processor.Plan(scenario)
+	}
+	return plans, nil
+}
+
+// Testers metadata of registered testers for authors of scenarios
+func (s *state) Testers() []models.TesterMeta {
+	return s.processor.Testers()
+}
+
 // Start manager
 func (s *state) Start() {
+	var ctx context.Context
+	ctx, s.cancel = context.WithCancel(context.Background())
+	checkpoints := make(chan *models.Checkpoint, checkpointsBuffer)
+	s.processor.SubscribeOnCheckpoints(ctx, checkpoints)
 	go s.loop()
 	go s.broadcast()
+	go s.collectCheckpoints(ctx, checkpoints)
+	if s.newConfig != nil && s.watchInterval > 0 {
+		go s.watch(ctx)
+	}
 }
 
 func (s *state) Stop() {
 	close(s.taskQueue)
 	<-s.stopped
+	s.cancel()
 }
 
 func (s *state) AllScenarios() (scenarios []models.Scenario) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 	return s.scenarios
 }
 
@@ -122,12 +272,53 @@ func (s *state) IsRunning() bool {
 	return atomic.LoadInt32(&s.running) == 1
 }
 
+// broadcast send completed tests to listeners and store them in their launch
 func (s *state) broadcast() {
 	for result := range s.completedTasks {
-		for _, listener := range s.listenersCompletedTests {
+		result := result
+		s.mu.Lock()
+		listeners := append([]chan<- *models.CompletedTest(nil), s.listenersCompletedTests...)
+		if launch := s.launch(result.launchID); launch != nil {
+			launch.CompletedTests = append(launch.CompletedTests, result.CompletedTest)
+		}
+		s.mu.Unlock()
+		for _, listener := range listeners {
 			listener <- &result.CompletedTest
 		}
-		s.currentLaunch.CompletedTests = append(s.currentLaunch.CompletedTests, result.CompletedTest)
+	}
+}
+
+// launch current or previous launch by id, caller must hold lock
+func (s *state) launch(launchID string) *models.LaunchInfo {
+	if s.currentLaunch != nil && s.currentLaunch.ID == launchID {
+		return s.currentLaunch
+	}
+	for _, launch := range s.history {
+		if launch.ID == launchID {
+			return launch
+		}
+	}
+	return nil
+}
+
+// collectCheckpoints store checkpoints of soak loads in launch
+func (s *state) collectCheckpoints(ctx context.Context, checkpoints <-chan *models.Checkpoint) {
+	for {
+		select {
+		case checkpoint := <-checkpoints:
+			s.addCheckpoint(checkpoint)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// addCheckpoint append checkpoint to its launch, last checkpoint can come after launch moved to history
+func (s *state) addCheckpoint(checkpoint *models.Checkpoint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if launch := s.launch(checkpoint.LaunchID); launch != nil {
+		launch.Checkpoints = append(launch.Checkpoints, *checkpoint)
 	}
 }
 
@@ -138,7 +329,8 @@ func (s *state) loop() {
 			continue
 		}
 		for _, scenario := range task.scenarios {
-			err = s.processor.Run(context.Background(), scenario, task.launchID)
+			var stopReason string
+			stopReason, err = s.processor.Run(context.Background(), scenario, task.launchID)
 			status := models.StatusCompleted
 			var e string
 			if err != nil {
@@ -151,6 +343,7 @@ func (s *state) loop() {
 					ScenarioName: scenario.Name,
 					Status:       status,
 					Error:        e,
+					StopReason:   stopReason,
 				},
 			}
 			s.completedTasks <- result
@@ -161,8 +354,9 @@ func (s *state) loop() {
 	s.stopped <- struct{}{}
 }
 
-// New construct new manager
-func New(conf scenariosGetter, proc processor, logger log.Logger) (man Manager, err error) {
+// New construct new manager, scenarios are validated and all problems are reported at once,
+// reload of config is enabled by options
+func New(conf scenariosGetter, proc processor, logger log.Logger, opts ...Option) (man Manager, err error) {
 	var scenarios []models.Scenario
 	scenarios, err = conf.GetScenarios()
 	scenariosIndex := make(map[string]int, len(scenarios))
@@ -172,7 +366,7 @@ func New(conf scenariosGetter, proc processor, logger log.Logger) (man Manager,
 	if err != nil {
 		return
 	}
-	return &state{
+	s := &state{
 		processor:      proc,
 		scenarios:      scenarios,
 		scenariosIndex: scenariosIndex,
@@ -180,5 +374,18 @@ func New(conf scenariosGetter, proc processor, logger log.Logger) (man Manager,
 		taskQueue:      make(chan task, 2),
 		stopped:        make(chan struct{}),
 		completedTasks: make(chan completedTask, taskPool),
-	}, nil
+	}
+	if files, ok := conf.(filesGetter); ok {
+		s.files = files.Files()
+	}
+	if s.clients, err = clientNames(conf); err != nil {
+		return nil, err
+	}
+	if err = s.validateScenarios(scenarios, s.clients); err != nil {
+		return nil, err
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
 }
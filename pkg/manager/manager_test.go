@@ -0,0 +1,125 @@
+package manager
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/lueurxax/e2e/pkg/log"
+	"github.com/lueurxax/e2e/pkg/models"
+)
+
+type scenarios []models.Scenario
+
+func (s scenarios) GetScenarios() ([]models.Scenario, error) {
+	return s, nil
+}
+
+// stressProcessor complete every scenario with stop reason and checkpoint of its launch
+type stressProcessor struct {
+	checkpoints chan<- *models.Checkpoint
+}
+
+func (p *stressProcessor) ValidateScenario(models.Scenario) error {
+	return nil
+}
+
+func (p *stressProcessor) Plan(scenario models.Scenario) *models.ScenarioPlan {
+	return &models.ScenarioPlan{Scenario: scenario.Name}
+}
+
+func (p *stressProcessor) Testers() []models.TesterMeta {
+	return nil
+}
+
+func (p *stressProcessor) Run(_ context.Context, scenario models.Scenario, launchID string) (string, error) {
+	p.checkpoints <- &models.Checkpoint{LaunchID: launchID, Scenario: scenario.Name}
+	return "ammo exhausted after 10 shots", nil
+}
+
+func (p *stressProcessor) SubscribeOnStressProgress(context.Context, chan<- *models.StressProgress) {}
+
+func (p *stressProcessor) SubscribeOnCheckpoints(_ context.Context, ch chan<- *models.Checkpoint) {
+	p.checkpoints = ch
+}
+
+func TestManagerLaunches(t *testing.T) {
+	logger := logrus.New()
+	logger.SetLevel(logrus.WarnLevel)
+	man, err := New(scenarios{{Name: "stress"}}, &stressProcessor{}, log.NewLogger(logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	man.Start()
+	completed := make(chan *models.CompletedTest, 4)
+	man.SubscribeOnCompletedTests(completed)
+
+	// readers of launch race with running launches
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for ctx.Err() == nil {
+			_ = man.CompletedScenarios()
+			_, _ = man.CurrentLaunch()
+			_, _ = man.Checkpoints("")
+		}
+	}()
+
+	launches := make([]string, 2)
+	for i := range launches {
+		if err = man.RunAllTests(); err != nil {
+			t.Fatal(err)
+		}
+		info, err := man.CurrentLaunch()
+		if err != nil {
+			t.Fatal(err)
+		}
+		launches[i] = info.ID
+		select {
+		case test := <-completed:
+			if test.StopReason != "ammo exhausted after 10 shots" {
+				t.Fatalf("unexpected stop reason %q", test.StopReason)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("test isn't completed")
+		}
+		// launch is stopped, when next launch can start
+		for start := time.Now(); man.(*state).IsRunning() && time.Since(start) < time.Second; {
+			time.Sleep(time.Millisecond)
+		}
+	}
+	cancel()
+	wg.Wait()
+
+	info, err := man.CurrentLaunch()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info.CompletedTests) != 1 || info.CompletedTests[0].StopReason == "" {
+		t.Fatalf("unexpected completed tests of launch %+v", info.CompletedTests)
+	}
+	info.CompletedTests[0].Status = models.StatusAborted
+	if man.CompletedScenarios()[0].Status == models.StatusAborted {
+		t.Fatal("copy of current launch changed launch")
+	}
+	// checkpoint is collected concurrently with completion of test
+	var checkpoints []models.Checkpoint
+	for start := time.Now(); len(checkpoints) == 0 && time.Since(start) < time.Second; time.Sleep(time.Millisecond) {
+		if checkpoints, err = man.Checkpoints(launches[0]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(checkpoints) != 1 || checkpoints[0].LaunchID != launches[0] {
+		t.Fatalf("unexpected checkpoints of previous launch %+v", checkpoints)
+	}
+	if history := man.(*state).History(); len(history) != 1 || history[0].ID != launches[0] ||
+		len(history[0].CompletedTests) != 1 {
+		t.Fatalf("unexpected history %+v", history)
+	}
+	man.Stop()
+}
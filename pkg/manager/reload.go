@@ -0,0 +1,125 @@
+package manager
+
+import (
+	"context"
+	"time"
+
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/config"
+	"github.com/lueurxax/e2e/pkg/models"
+)
+
+// ReloadableConfig config read from its files on every reload, config.Configurator implements it
+type ReloadableConfig interface {
+	Read() error
+	Validate() error
+	Init()
+	GetScenarios() ([]models.Scenario, error)
+	Files() []string
+	Close() error
+}
+
+type filesGetter interface {
+	Files() []string
+}
+
+// Option of manager
+type Option func(s *state)
+
+// WithReload enable reload of config: function builds new config on every reload and its scenarios
+// are validated like at start. Clients and testers are built at start, reload swaps scenarios.
+func WithReload(newConfig func() ReloadableConfig) Option {
+	return func(s *state) {
+		s.newConfig = newConfig
+	}
+}
+
+// WithWatch reload config on change of its files, files are polled with interval
+func WithWatch(interval time.Duration) Option {
+	return func(s *state) {
+		s.watchInterval = interval
+	}
+}
+
+// ReloadConfig read and validate config, scenarios are swapped only when config is valid,
+// running launch keeps its scenarios and next launches use new ones
+func (s *state) ReloadConfig() (*models.ConfigReload, error) {
+	if s.newConfig == nil {
+		return nil, common.ErrReloadDisabled()
+	}
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+	conf := s.newConfig()
+	// clients of new config are built only for validation, testers use clients built at start
+	defer func() {
+		_ = conf.Close()
+	}()
+	scenarios, err := s.readScenarios(conf)
+	if err != nil {
+		s.log.WithError(err).Warn("config isn't reloaded")
+		return nil, err
+	}
+	s.setScenarios(scenarios)
+	names := make([]string, len(scenarios))
+	for i, scenario := range scenarios {
+		names[i] = scenario.Name
+	}
+	s.log.WithField("scenarios", len(scenarios)).Info("config reloaded")
+	return &models.ConfigReload{Scenarios: names, Time: time.Now().Format(time.RFC3339)}, nil
+}
+
+func (s *state) readScenarios(conf ReloadableConfig) ([]models.Scenario, error) {
+	err := conf.Read()
+	s.setFiles(conf.Files())
+	if err != nil {
+		return nil, err
+	}
+	if err = conf.Validate(); err != nil {
+		return nil, err
+	}
+	conf.Init()
+	scenarios, err := conf.GetScenarios()
+	if err != nil {
+		return nil, err
+	}
+	// testers use clients built at start, so stages are checked against them
+	if err = s.validateScenarios(scenarios, s.clients); err != nil {
+		return nil, err
+	}
+	return scenarios, nil
+}
+
+// setScenarios swap scenarios atomically
+func (s *state) setScenarios(scenarios []models.Scenario) {
+	index := make(map[string]int, len(scenarios))
+	for i, scenario := range scenarios {
+		index[scenario.Name] = i
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.scenarios = scenarios
+	s.scenariosIndex = index
+}
+
+func (s *state) setFiles(files []string) {
+	if len(files) == 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files = files
+}
+
+func (s *state) watchedFiles() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.files
+}
+
+// watch reload config on change of its files until context is done, failed reload keeps watching
+// files of failed config, so fixed file is reloaded
+func (s *state) watch(ctx context.Context) {
+	config.Watch(ctx, s.watchInterval, s.watchedFiles, func() {
+		_, _ = s.ReloadConfig()
+	})
+}
@@ -0,0 +1,93 @@
+package manager
+
+import (
+	"fmt"
+
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/clientfactory"
+	"github.com/lueurxax/e2e/pkg/models"
+)
+
+type clientsGetter interface {
+	Clients() (clients *clientfactory.Set, err error)
+}
+
+type issuesGetter interface {
+	Issues() []string
+}
+
+// clientNames names of clients of config, nil if config doesn't build clients
+func clientNames(conf interface{}) (map[string]struct{}, error) {
+	getter, ok := conf.(clientsGetter)
+	if !ok {
+		return nil, nil
+	}
+	clients, err := getter.Clients()
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[string]struct{}, len(clients.Configs()))
+	for _, client := range clients.Configs() {
+		names[client.Name] = struct{}{}
+	}
+	return names, nil
+}
+
+// validateScenarios validate testers, fields and clients of every scenario and report all problems at once,
+// clients aren't checked if they are unknown
+func (s *state) validateScenarios(scenarios []models.Scenario, clients map[string]struct{}) error {
+	var issues []string
+	for _, scenario := range scenarios {
+		issues = append(issues, s.scenarioIssues(scenario, clients)...)
+	}
+	if len(issues) > 0 {
+		return common.ErrConfigIssues(issues)
+	}
+	return nil
+}
+
+func (s *state) scenarioIssues(scenario models.Scenario, clients map[string]struct{}) (issues []string) {
+	prefix := fmt.Sprintf("scenario %s: ", scenario.Name)
+	if err := s.processor.ValidateScenario(scenario); err != nil {
+		if report, ok := err.(issuesGetter); ok {
+			for _, issue := range report.Issues() {
+				issues = append(issues, prefix+issue)
+			}
+		} else {
+			issues = append(issues, prefix+err.Error())
+		}
+	}
+	if clients == nil {
+		return issues
+	}
+	for _, stage := range namedStages(scenario) {
+		if stage.Client == "" {
+			continue
+		}
+		if _, ok := clients[stage.Client]; !ok {
+			issues = append(issues, fmt.Sprintf("%s%s: unknown client %s", prefix, stage.name, stage.Client))
+		}
+	}
+	return issues
+}
+
+type namedStage struct {
+	name string
+	*models.Stage
+}
+
+// namedStages stages of scenario in order of run
+func namedStages(scenario models.Scenario) []namedStage {
+	stages := make([]namedStage, 0, len(scenario.BeforeTest)+3)
+	for i := range scenario.BeforeTest {
+		stages = append(stages, namedStage{fmt.Sprintf("before_test[%d]", i), &scenario.BeforeTest[i]})
+	}
+	for _, stage := range []namedStage{
+		{"action", scenario.Action}, {"check", scenario.Check}, {"after_test", scenario.AfterTest},
+	} {
+		if stage.Stage != nil {
+			stages = append(stages, stage)
+		}
+	}
+	return stages
+}
@@ -0,0 +1,21 @@
+package models
+
+// Checkpoint periodic summary of soak load, latencies are in milliseconds and are measured since previous checkpoint
+type Checkpoint struct {
+	LaunchID  string  `json:"launchId"`
+	Scenario  string  `json:"scenario"`
+	Time      string  `json:"time"`
+	Elapsed   string  `json:"elapsed"`
+	Requests  int     `json:"requests"`
+	Errors    int     `json:"errors"`
+	ErrorRate float64 `json:"errorRate"`
+	Rps       float64 `json:"rps"`
+	P50       float64 `json:"p50"`
+	P90       float64 `json:"p90"`
+	P99       float64 `json:"p99"`
+	Max       float64 `json:"max"`
+	// HeapAlloc and Sys memory of runner in bytes
+	HeapAlloc  int64 `json:"heapAlloc"`
+	Sys        int64 `json:"sys"`
+	Goroutines int   `json:"goroutines"`
+}
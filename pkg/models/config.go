@@ -4,6 +4,12 @@ import "github.com/lueurxax/e2e/common"
 
 // Config of config structure
 type Config struct {
-	Clients []common.Client `yaml:"clients"`
-	Tests   []Test          `yaml:"tests"`
+	Clients     []common.Client     `yaml:"clients"`
+	HTTPTesters []common.HTTPTester `yaml:"http_testers"`
+	GRPCTesters []common.GRPCTester `yaml:"grpc_testers"`
+	// ScriptTesters testers written in Starlark
+	ScriptTesters []common.ScriptTester `yaml:"script_testers"`
+	Tests         []Test                `yaml:"tests"`
+	// PluginsDir directory of executables of tester plugins, relative to config
+	PluginsDir string `yaml:"plugins_dir"`
 }
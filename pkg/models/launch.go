@@ -16,6 +16,7 @@ type LaunchInfo struct {
 	Status
 	CompletedTests []CompletedTest
 	Errors         []ErrorTest
+	Checkpoints    []Checkpoint
 }
 
 // NewLaunchInfo construct LaunchInfo struct
@@ -25,5 +26,6 @@ func NewLaunchInfo() *LaunchInfo {
 		ID:             launchID.String(),
 		Status:         StatusRunning,
 		CompletedTests: make([]CompletedTest, 0),
+		Checkpoints:    make([]Checkpoint, 0),
 	}
 }
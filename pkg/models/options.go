@@ -2,5 +2,8 @@ package models
 
 // Options parameters
 type Options struct {
-	Conf *Test
+	Conf     *Test
+	LaunchID string
+	// StopReason why shooting of stress load stopped, it is set by stress worker
+	StopReason string
 }
@@ -0,0 +1,37 @@
+package models
+
+// ScenarioPlan resolved execution plan of scenario, it is built without requests
+type ScenarioPlan struct {
+	Scenario string `json:"scenario"`
+	// ShootCount count of states of scenario computed from stress load or repeat
+	ShootCount int  `json:"shootCount"`
+	StressLoad bool `json:"stressLoad"`
+	Streaming  bool `json:"streaming"`
+	// Params global params after Prepare, generated params have values $random and $increment
+	Params []PlanParam `json:"params"`
+	Stages []StagePlan `json:"stages"`
+	Issues []string    `json:"issues"`
+}
+
+// PlanParam global param of scenario
+type PlanParam struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// StagePlan stage of plan in order of run with flow of fields of state
+type StagePlan struct {
+	// Stage place of stage in scenario, like before_test[0] or action
+	Stage         string `json:"stage"`
+	Tester        string `json:"tester"`
+	Client        string `json:"client"`
+	RequestsCount int    `json:"requestsCount"`
+	WantError     bool   `json:"wantError"`
+	Error         string `json:"error"`
+	// Params own params of stage
+	Params   []string `json:"params"`
+	Required []string `json:"required"`
+	// Missing required fields which neither params nor previous stages set
+	Missing  []string `json:"missing"`
+	Returned []string `json:"returned"`
+}
@@ -0,0 +1,16 @@
+package models
+
+// StressProgress live stats of running stress load
+type StressProgress struct {
+	LaunchID           string `json:"launchId"`
+	Scenario           string `json:"scenario"`
+	ResponsesPerSecond int    `json:"responsesPerSecond"`
+	RequestsPerSecond  int    `json:"requestsPerSecond"`
+	ActiveUsers        int    `json:"activeUsers"`
+	ActiveRequests     int    `json:"activeRequests"`
+	Errors             int    `json:"errors"`
+	// Dropped samples lost by aggregator because of queue overflow
+	Dropped int `json:"dropped"`
+	// StopReason is set in the last report of run, when shooting stopped
+	StopReason string `json:"stopReason"`
+}
@@ -0,0 +1,7 @@
+package models
+
+// ConfigReload result of reload of config, scenarios of reloaded config are active for next launches
+type ConfigReload struct {
+	Scenarios []string `json:"scenarios"`
+	Time      string   `json:"time"`
+}
@@ -1,6 +1,10 @@
 package models
 
-import "github.com/lueurxax/e2e/common"
+import (
+	"context"
+
+	"github.com/lueurxax/e2e/common"
+)
 
 // Stage of test scenario
 type Stage struct {
@@ -20,6 +24,37 @@ type State interface {
 	AddToState(selectors []StateSelector, params ...map[string]interface{}) []StateSelector
 }
 
+// StateTransfer optional interface of State for shipping state slices to remote agents
+type StateTransfer interface {
+	Dump(selectors []StateSelector) []map[string]interface{}
+	Load(params []map[string]interface{}) []StateSelector
+}
+
+// RawState optional interface of State for testers without generated methods, like declarative testers
+type RawState interface {
+	// Raw return raw params of selected state
+	Raw(selector StateSelector) map[string]interface{}
+	// NewRaw construct result state of selected state from values converted to types of state fields
+	NewRaw(selector StateSelector, values map[string]interface{}) (StateSelector, error)
+}
+
+// StateStream optional interface of State for lazy generation of stress load states with constant memory
+type StateStream interface {
+	// NewStream construct stream, offset is index of the first generated state, so generated values like
+	// $increment continue values of prepared ammo
+	NewStream(
+		slots, offset int, init common.InitState, base StateSelector, params ...map[string]interface{},
+	) Stream
+}
+
+// Stream of lazily generated states in bounded ring of slots
+type Stream interface {
+	// Next wait free slot and generate state for next shot, record overrides generated values
+	Next(ctx context.Context, record map[string]interface{}) (StateSelector, error)
+	// Release return slot of finished shot
+	Release(selector StateSelector)
+}
+
 type StateSelector interface {
 	Index() int
 }
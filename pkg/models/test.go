@@ -1,12 +1,16 @@
 package models
 
-import "github.com/lueurxax/e2e/common"
+import (
+	"github.com/lueurxax/e2e/common"
+)
 
 // CompletedTest model
 type CompletedTest struct {
 	ScenarioName string `json:"scenarioName"`
 	Status       Status `json:"status"`
 	Error        string `json:"error"`
+	// StopReason why shooting of stress load stopped, empty for scenarios without stress load
+	StopReason string `json:"stopReason"`
 }
 
 // Test config struct
@@ -82,11 +86,18 @@ func (t *Test) Prepare() {
 //	 |****|
 //	______
 //	duration
+//
+// for closed model every user makes at most one request per think time
 func (t *Test) computeShootCount() {
 	if t.StressLoad == nil {
 		return
 	}
-	t.StressLoad.ShootCount = (t.StressLoad.From/2 + t.StressLoad.To/2) * t.StressLoad.Duration * 2
+	if t.StressLoad.IsClosed() {
+		duration := t.StressLoad.Duration.Duration()
+		t.StressLoad.ShootCount = t.StressLoad.Instances * int(duration/t.StressLoad.ThinkTime+1)
+		return
+	}
+	t.StressLoad.ShootCount = (t.StressLoad.From/2 + t.StressLoad.To/2) * int(t.StressLoad.Duration.Duration().Seconds()) * 2
 }
 
 // Validate config
@@ -94,5 +105,8 @@ func (t *Test) Validate() error {
 	if t.StressLoad != nil && t.Repeat > 1 {
 		return common.ErrInvalidConfig("cannot use stress load with repeated requests")
 	}
+	if t.StressLoad != nil {
+		return t.StressLoad.Validate()
+	}
 	return nil
 }
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lueurxax/e2e/common"
+)
+
+// open model prepares twice as many shoots as schedule plans, closed model prepares
+// Instances*(duration/think+1) shoots, at most one shoot per think time of every user
+func TestStressLoadModels(t *testing.T) {
+	tests := []struct {
+		name       string
+		load       common.StressLoad
+		shootCount int
+		targetRPS  float64
+		invalid    bool
+	}{
+		{
+			name:       "open model by default",
+			load:       common.StressLoad{Duration: common.Duration(10 * time.Second), From: 10, To: 30},
+			shootCount: 400,
+			targetRPS:  20,
+		},
+		{
+			name:       "open model",
+			load:       common.StressLoad{Model: common.ModelOpen, Duration: common.Duration(time.Minute), From: 100, To: 100},
+			shootCount: 12000,
+			targetRPS:  100,
+		},
+		{
+			name: "closed model",
+			load: common.StressLoad{
+				Model: common.ModelClosed, Instances: 5, Duration: common.Duration(10 * time.Second), ThinkTime: time.Second,
+			},
+			shootCount: 55,
+			targetRPS:  5,
+		},
+		{
+			name: "closed model with think time longer than duration",
+			load: common.StressLoad{
+				Model: common.ModelClosed, Instances: 4, Duration: common.Duration(time.Second), ThinkTime: 2 * time.Second,
+			},
+			shootCount: 4,
+			targetRPS:  2,
+		},
+		{
+			name: "closed model with fractional think time",
+			load: common.StressLoad{
+				Model: common.ModelClosed, Instances: 2, Duration: common.Duration(time.Second), ThinkTime: 300 * time.Millisecond,
+			},
+			shootCount: 8,
+			targetRPS:  2 / 0.3,
+		},
+		{
+			name:    "closed model without think time",
+			load:    common.StressLoad{Model: common.ModelClosed, Instances: 2, Duration: common.Duration(time.Second)},
+			invalid: true,
+		},
+		{
+			name:    "closed model without instances",
+			load:    common.StressLoad{Model: common.ModelClosed, Duration: common.Duration(time.Second), ThinkTime: time.Second},
+			invalid: true,
+		},
+		{
+			name:    "unknown model",
+			load:    common.StressLoad{Model: "poisson", Duration: common.Duration(time.Second)},
+			invalid: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			load := tt.load
+			test := &Test{Name: "stress", StressLoad: &load}
+			err := test.Validate()
+			if tt.invalid {
+				if err == nil {
+					t.Fatal("expected invalid load model")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			test.Prepare()
+			if load.GetShootCount() != tt.shootCount {
+				t.Fatalf("expected %d shoots, got %d", tt.shootCount, load.GetShootCount())
+			}
+			if rps := load.TargetRPS(); rps < tt.targetRPS-1e-9 || rps > tt.targetRPS+1e-9 {
+				t.Fatalf("expected target rps %v, got %v", tt.targetRPS, rps)
+			}
+		})
+	}
+}
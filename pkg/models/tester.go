@@ -11,3 +11,30 @@ type Tester interface {
 	ReturnedFields() (fields []string)
 	Run(ctx context.Context, client string, selector StateSelector, opts *Options) (StateSelector, error)
 }
+
+// TesterInfo optional interface of Tester for discovery of testers by scenario authors
+type TesterInfo interface {
+	Description() (description string)
+	// ClientKinds kinds of clients supported by tester, like http, any client if empty
+	ClientKinds() (kinds []string)
+}
+
+// FieldTyped optional interface of Tester or State with types of fields of state, like int or string
+type FieldTyped interface {
+	FieldTypes() (types map[string]string)
+}
+
+// TesterMeta metadata of registered tester
+type TesterMeta struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description"`
+	Required    []FieldMeta `json:"required"`
+	Returned    []FieldMeta `json:"returned"`
+	Clients     []string    `json:"clients"`
+}
+
+// FieldMeta field of state used by tester, type is empty if neither tester nor state knows it
+type FieldMeta struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
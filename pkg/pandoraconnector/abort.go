@@ -0,0 +1,127 @@
+package pandoraconnector
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lueurxax/e2e/common"
+)
+
+type windowSample struct {
+	timestamp time.Time
+	latency   time.Duration
+	failed    bool
+}
+
+// abortWatcher watch samples of stress run and cancel engine when abort criteria tripped
+type abortWatcher struct {
+	mu        sync.Mutex
+	criteria  *common.AbortCriteria
+	errorRate float64
+	cancel    context.CancelFunc
+
+	window       []windowSample
+	failures     int
+	latencySum   time.Duration
+	consecutive  int
+	reason       string
+	stoppedAfter time.Duration
+	started      time.Time
+}
+
+// reset watcher for new run, nil criteria disable watching
+func (w *abortWatcher) reset(criteria *common.AbortCriteria, cancel context.CancelFunc) (err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.criteria, w.cancel, w.started = criteria, cancel, time.Now()
+	w.window, w.failures, w.latencySum, w.consecutive = nil, 0, 0, 0
+	w.reason, w.stoppedAfter, w.errorRate = "", 0, 0
+	if criteria != nil {
+		w.errorRate, err = criteria.GetErrorRate()
+	}
+	return
+}
+
+func (w *abortWatcher) add(data *common.RequestData) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.criteria == nil || w.reason != "" {
+		return
+	}
+	failed := data.Code != successCode
+	if failed {
+		w.consecutive++
+	} else {
+		w.consecutive = 0
+	}
+	w.push(windowSample{timestamp: data.Timestamp, latency: data.Latency, failed: failed})
+
+	if reason := w.check(); reason != "" {
+		w.reason = reason
+		w.stoppedAfter = time.Since(w.started)
+		w.cancel()
+	}
+}
+
+// push sample to window sorted by timestamps, samples of instances may come slightly out of order
+func (w *abortWatcher) push(sample windowSample) {
+	i := sort.Search(len(w.window), func(i int) bool {
+		return w.window[i].timestamp.After(sample.timestamp)
+	})
+	w.window = append(w.window, sample)
+	if i < len(w.window)-1 {
+		copy(w.window[i+1:], w.window[i:])
+		w.window[i] = sample
+	}
+	w.latencySum += sample.latency
+	if sample.failed {
+		w.failures++
+	}
+	border := w.window[len(w.window)-1].timestamp.Add(-w.criteria.GetWindow())
+	for i = 0; i < len(w.window) && w.window[i].timestamp.Before(border); i++ {
+		w.latencySum -= w.window[i].latency
+		if w.window[i].failed {
+			w.failures--
+		}
+	}
+	w.window = w.window[i:]
+}
+
+func (w *abortWatcher) check() string {
+	if w.criteria.ConsecutiveFailures > 0 && w.consecutive >= w.criteria.ConsecutiveFailures {
+		return fmt.Sprintf("%d consecutive failures", w.consecutive)
+	}
+	count := len(w.window)
+	if count == 0 || count < w.criteria.GetMinRequests() {
+		return ""
+	}
+	if w.errorRate > 0 {
+		if rate := float64(w.failures) / float64(count); rate > w.errorRate {
+			return fmt.Sprintf(
+				"error rate %.2f%% exceeded %.2f%% in %s window",
+				rate*100, w.errorRate*100, w.criteria.GetWindow())
+		}
+	}
+	if w.criteria.LatencyCeiling > 0 {
+		if avg := w.latencySum / time.Duration(count); avg > w.criteria.LatencyCeiling {
+			return fmt.Sprintf(
+				"average latency %s exceeded ceiling %s in %s window",
+				avg, w.criteria.LatencyCeiling, w.criteria.GetWindow())
+		}
+	}
+	return ""
+}
+
+// aborted return reason of abort, empty if run was not aborted
+func (w *abortWatcher) aborted() (reason string, after time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.reason, w.stoppedAfter
+}
+
+func newAbortWatcher() *abortWatcher {
+	return &abortWatcher{}
+}
@@ -0,0 +1,148 @@
+package pandoraconnector
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lueurxax/e2e/common"
+)
+
+type shot struct {
+	offset  time.Duration
+	latency time.Duration
+	failed  bool
+}
+
+// shots count of shots every 10ms starting from offset
+func shots(count int, offset, latency time.Duration, failed bool) []shot {
+	result := make([]shot, count)
+	for i := range result {
+		result[i] = shot{offset: offset + time.Duration(i)*10*time.Millisecond, latency: latency, failed: failed}
+	}
+	return result
+}
+
+func join(parts ...[]shot) []shot {
+	result := make([]shot, 0)
+	for _, part := range parts {
+		result = append(result, part...)
+	}
+	return result
+}
+
+func TestAbortWatcher(t *testing.T) {
+	tests := []struct {
+		name     string
+		criteria common.AbortCriteria
+		shots    []shot
+		reason   string
+	}{
+		{
+			name:     "first failure doesn't trip error rate",
+			criteria: common.AbortCriteria{ErrorRate: "50%"},
+			shots:    join(shots(1, 0, time.Millisecond, true), shots(98, time.Second, time.Millisecond, false)),
+		},
+		{
+			name:     "error rate after default min requests",
+			criteria: common.AbortCriteria{ErrorRate: "50%"},
+			shots:    shots(common.DefaultAbortMinRequests, 0, time.Millisecond, true),
+			reason:   "error rate 100.00% exceeded 50.00% in 10s window",
+		},
+		{
+			name:     "error rate after configured min requests",
+			criteria: common.AbortCriteria{ErrorRate: "0.1", MinRequests: 10},
+			shots:    join(shots(8, 0, time.Millisecond, false), shots(2, time.Second, time.Millisecond, true)),
+			reason:   "error rate 20.00% exceeded 10.00% in 10s window",
+		},
+		{
+			name:     "failures out of window",
+			criteria: common.AbortCriteria{ErrorRate: "10%", MinRequests: 10, Window: time.Second},
+			shots:    join(shots(5, 0, time.Millisecond, true), shots(10, 5*time.Second, time.Millisecond, false)),
+		},
+		{
+			name:     "consecutive failures",
+			criteria: common.AbortCriteria{ConsecutiveFailures: 3},
+			shots: join(
+				shots(2, 0, time.Millisecond, true),
+				shots(1, time.Second, time.Millisecond, false),
+				shots(3, 2*time.Second, time.Millisecond, true),
+			),
+			reason: "3 consecutive failures",
+		},
+		{
+			name:     "latency ceiling",
+			criteria: common.AbortCriteria{LatencyCeiling: 100 * time.Millisecond, MinRequests: 5},
+			shots:    join(shots(4, 0, time.Second, false), shots(1, time.Second, time.Second, false)),
+			reason:   "average latency 1s exceeded ceiling 100ms in 10s window",
+		},
+		{
+			name:     "latency below ceiling",
+			criteria: common.AbortCriteria{LatencyCeiling: 100 * time.Millisecond, MinRequests: 5},
+			shots:    shots(20, 0, 50*time.Millisecond, false),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			criteria := tt.criteria
+			if err := criteria.Validate(); err != nil {
+				t.Fatal(err)
+			}
+			watcher := newAbortWatcher()
+			var cancelled bool
+			if err := watcher.reset(&criteria, func() { cancelled = true }); err != nil {
+				t.Fatal(err)
+			}
+			start := time.Now()
+			for _, s := range tt.shots {
+				code := successCode
+				if s.failed {
+					code = 500
+				}
+				watcher.add(&common.RequestData{Code: code, Latency: s.latency, Timestamp: start.Add(s.offset)})
+			}
+			reason, _ := watcher.aborted()
+			if reason != tt.reason || cancelled != (tt.reason != "") {
+				t.Fatalf("expected reason %q, got %q (cancelled %v)", tt.reason, reason, cancelled)
+			}
+		})
+	}
+}
+
+func TestAbortWatcherOutOfOrderSamples(t *testing.T) {
+	criteria := &common.AbortCriteria{ErrorRate: "40%", MinRequests: 4, Window: time.Second}
+	watcher := newAbortWatcher()
+	if err := watcher.reset(criteria, func() {}); err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	add := func(offset time.Duration, code int) {
+		watcher.add(&common.RequestData{Code: code, Latency: time.Millisecond, Timestamp: start.Add(offset)})
+	}
+	add(2*time.Second, successCode)
+	add(2100*time.Millisecond, successCode)
+	// late failures older than window are ignored
+	add(500*time.Millisecond, 500)
+	add(900*time.Millisecond, 500)
+	add(2200*time.Millisecond, successCode)
+	if reason, _ := watcher.aborted(); reason != "" {
+		t.Fatalf("samples out of window tripped abort: %s", reason)
+	}
+	if len(watcher.window) != 3 {
+		t.Fatalf("unexpected window of %d samples", len(watcher.window))
+	}
+
+	// late failures inside window are counted
+	add(1500*time.Millisecond, 500)
+	add(1900*time.Millisecond, 500)
+	add(1800*time.Millisecond, 500)
+	reason, _ := watcher.aborted()
+	if !strings.HasPrefix(reason, "error rate 50.00% exceeded 40.00%") {
+		t.Fatalf("unexpected reason %q", reason)
+	}
+	for i := 1; i < len(watcher.window); i++ {
+		if watcher.window[i].timestamp.Before(watcher.window[i-1].timestamp) {
+			t.Fatal("window isn't sorted by timestamps")
+		}
+	}
+}
@@ -4,12 +4,16 @@ import (
 	"context"
 
 	"github.com/yandex/pandora/core"
+	"github.com/yandex/pandora/core/register"
 
 	"github.com/lueurxax/e2e/common"
-	"github.com/lueurxax/e2e/pkg/log"
 )
 
-const s3Aggregator = "rest_aggregator"
+const (
+	s3Aggregator = "rest_aggregator"
+	// aggregatorBuffer size of samples queue, samples are dropped when it is full
+	aggregatorBuffer = 4096
+)
 
 // Aggregator is routine that aggregates Samples from all Pool Instances.
 // Usually aggregator is shooting result reporter, that writes Reported Samples
@@ -22,8 +26,8 @@ type Aggregator interface {
 	// of goroutine races.
 	// In case of ctx cancel, SHOULD return nil, but MAY ctx.Err(), or error caused ctx.Err()
 	// in terms of github.com/pkg/errors.Cause.
-	// In case of any dropped RequestData (unhandled because of RequestData queue overflow) Run SHOULD return
-	// error describing how many samples were dropped.
+	// Dropped RequestData (unhandled because of RequestData queue overflow) are counted by collector of run,
+	// they aren't an error of Run, so engine isn't failed by them.
 	Run(ctx context.Context, deps core.AggregatorDeps) error
 	// Report reports sample to aggregator. SHOULD be lightweight and not blocking,
 	// so Instance can Shoot as soon as possible.
@@ -38,10 +42,14 @@ type Aggregator interface {
 	Report(s core.Sample)
 }
 
+// aggregatorConfig per run config of aggregator plugin
+type aggregatorConfig struct {
+	Collector sampleCollector `config:"collector" validate:"required"`
+}
+
 type aggregator struct {
-	log   log.Logger
-	sink  chan core.Sample
-	meter common.Meter
+	sink      chan core.Sample
+	collector sampleCollector
 }
 
 func (s *aggregator) Run(ctx context.Context, deps core.AggregatorDeps) error {
@@ -65,18 +73,22 @@ loop:
 	}
 }
 
+// Report sample without blocking of gun, sample is dropped and counted if queue is full
 func (s *aggregator) Report(sample core.Sample) {
-	s.sink <- sample
+	select {
+	case s.sink <- sample:
+	default:
+		s.collector.drop()
+	}
 }
 
 func (s *aggregator) handle(sample core.Sample) {
-	data := sample.(*common.RequestData)
-	s.meter.AddRequest(data)
+	s.collector.collect(sample.(*common.RequestData))
 }
 
-// News3Aggregator construct new S3 Aggregator
-func News3Aggregator(log log.Logger, metrics common.Meter) func() Aggregator {
-	return func() Aggregator {
-		return &aggregator{log: log, sink: make(chan core.Sample, 128), meter: metrics}
-	}
+// RegisterAggregator register aggregator plugin, samples are handled by collector of run
+func RegisterAggregator() {
+	register.Aggregator(s3Aggregator, func(conf aggregatorConfig) Aggregator {
+		return &aggregator{sink: make(chan core.Sample, aggregatorBuffer), collector: conf.Collector}
+	})
 }
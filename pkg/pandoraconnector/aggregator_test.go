@@ -0,0 +1,58 @@
+package pandoraconnector
+
+import (
+	"context"
+	"testing"
+
+	"github.com/yandex/pandora/core"
+
+	"github.com/lueurxax/e2e/common"
+)
+
+type countingMeter struct {
+	requests int
+}
+
+func (m *countingMeter) NewLaunch(string) {}
+
+func (m *countingMeter) NewStress(string, common.StressLoad) {}
+
+func (m *countingMeter) AddRequest(*common.RequestData) {
+	m.requests++
+}
+
+func (m *countingMeter) Reset() {}
+
+func TestAggregatorCountDroppedSamples(t *testing.T) {
+	meter := &countingMeter{}
+	run := newStressRun(meter)
+	aggr := &aggregator{sink: make(chan core.Sample, 2), collector: run}
+	for i := 0; i < 5; i++ {
+		sample := common.NewRequestData("Test")
+		sample.SetProtoCode(successCode)
+		aggr.Report(sample)
+	}
+
+	// queue is handled after cancel, dropped samples don't fail run
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := aggr.Run(ctx, core.AggregatorDeps{}); err != nil {
+		t.Fatal(err)
+	}
+	if meter.requests != 2 {
+		t.Fatalf("handled %d samples", meter.requests)
+	}
+	if dropped := run.stats.droppedCount(); dropped != 3 {
+		t.Fatalf("dropped %d samples", dropped)
+	}
+	summary := run.stats.summary()
+	if summary.Dropped != 3 || summary.ErrorRate() != 0.6 {
+		t.Fatalf("unexpected summary %+v with error rate %v", summary, summary.ErrorRate())
+	}
+	if checkpoint := run.stats.checkpoint(); checkpoint.Dropped != 3 {
+		t.Fatalf("checkpoint has %d dropped samples", checkpoint.Dropped)
+	}
+	if checkpoint := run.stats.checkpoint(); checkpoint.Dropped != 0 {
+		t.Fatalf("dropped samples are counted in next checkpoint")
+	}
+}
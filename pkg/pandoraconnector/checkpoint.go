@@ -0,0 +1,66 @@
+package pandoraconnector
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/lueurxax/e2e/pkg/models"
+)
+
+// startCheckpoints emit checkpoints of soak load every checkpoint interval and the last one when run is finished
+func (c *connector) startCheckpoints(run *stressRun, opts *models.Options) (cancel chan struct{}) {
+	cancel = make(chan struct{})
+	if !opts.Conf.StressLoad.IsSoak() {
+		return
+	}
+	go func(cancel chan struct{}) {
+		ticker := time.NewTicker(opts.Conf.StressLoad.Soak.GetCheckpoint())
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				c.checkpoint(run, opts)
+			case <-cancel:
+				c.checkpoint(run, opts)
+				return
+			}
+		}
+	}(cancel)
+	return
+}
+
+// checkpoint summarize samples since previous checkpoint with memory of runner, log and publish it
+func (c *connector) checkpoint(run *stressRun, opts *models.Options) {
+	summary := run.stats.checkpoint()
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+	checkpoint := &models.Checkpoint{
+		LaunchID:   opts.LaunchID,
+		Scenario:   opts.Conf.Name,
+		Time:       time.Now().Format(time.RFC3339),
+		Elapsed:    time.Since(run.started).Round(time.Second).String(),
+		Requests:   summary.Requests,
+		Errors:     summary.Errors,
+		ErrorRate:  summary.ErrorRate(),
+		Rps:        summary.RPS(),
+		P50:        milliseconds(summary.Percentile(50)),
+		P90:        milliseconds(summary.Percentile(90)),
+		P99:        milliseconds(summary.Percentile(99)),
+		Max:        milliseconds(summary.Max()),
+		HeapAlloc:  int64(mem.HeapAlloc),
+		Sys:        int64(mem.Sys),
+		Goroutines: runtime.NumGoroutine(),
+	}
+	c.logger.WithField("scenario", checkpoint.Scenario).WithField("id", checkpoint.LaunchID).
+		WithField("elapsed", checkpoint.Elapsed).
+		WithField("requests", checkpoint.Requests).
+		WithField("error_rate", checkpoint.ErrorRate).
+		WithField("p99", checkpoint.P99).
+		WithField("heap", checkpoint.HeapAlloc).
+		Info("soak checkpoint")
+	c.checkpoints.Publish(checkpoint)
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
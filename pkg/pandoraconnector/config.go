@@ -1,7 +1,7 @@
 package pandoraconnector
 
 import (
-	"strconv"
+	"sync"
 
 	uuid "github.com/satori/go.uuid"
 	pandoraconfig "github.com/yandex/pandora/core/config"
@@ -10,9 +10,32 @@ import (
 	"github.com/lueurxax/e2e/common"
 )
 
+// decodeMu serialize decoding of pandora configs, decoder compiles global hooks lazily and isn't safe
+// for concurrent stress runs
+var decodeMu sync.Mutex
+
 // TODO will replaced with constructor for engine.Config
-func initConfig(conf common.StressLoad) (engineConf *engine.Config, err error) {
-	duration := strconv.Itoa(conf.Duration) + "s"
+func initConfig(
+	conf common.StressLoad,
+	gun gunConfigurator,
+	provider providerConfigurator,
+	run stressRunner,
+) (engineConf *engine.Config, err error) {
+	duration := conf.Duration.String()
+
+	// closed model has no rps schedule, users shoot one by one with think time in gun
+	rps := map[string]interface{}{
+		"duration": duration,
+		"type":     "line",
+		"from":     conf.From,
+		"to":       conf.To,
+	}
+	if conf.IsClosed() {
+		rps = map[string]interface{}{
+			"duration": duration,
+			"type":     "unlimited",
+		}
+	}
 
 	id := uuid.NewV4()
 
@@ -21,22 +44,21 @@ func initConfig(conf common.StressLoad) (engineConf *engine.Config, err error) {
 			{
 				"id": id.String(),
 				"gun": map[string]interface{}{
-					"type": S3Gun,
+					"type":         S3Gun,
+					"configurator": gun,
 				},
 				"ammo": map[string]interface {
 				}{
-					"type": S3Provider,
+					"type":         S3Provider,
+					"configurator": provider,
+					"recorder":     run,
 				},
 				"result": map[string]interface {
 				}{
-					"type": s3Aggregator,
-				},
-				"rps": map[string]interface{}{
-					"duration": duration,
-					"type":     "line",
-					"from":     conf.From,
-					"to":       conf.To,
+					"type":      s3Aggregator,
+					"collector": run,
 				},
+				"rps": rps,
 				"startup": map[string]interface {
 				}{
 					"type":  "once",
@@ -50,6 +72,8 @@ func initConfig(conf common.StressLoad) (engineConf *engine.Config, err error) {
 		Engine engine.Config `config:",squash"`
 	}{}
 
+	decodeMu.Lock()
+	defer decodeMu.Unlock()
 	if err := pandoraconfig.DecodeAndValidate(confMap, confStruct); err != nil {
 		return nil, err
 	}
@@ -3,6 +3,7 @@ package pandoraconnector
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/yandex/pandora/core/engine"
@@ -18,6 +19,9 @@ import (
 
 const compositeScheduleKey = "composite"
 
+// registerOnce guards global pandora plugin registry, plugins are registered once per process
+var registerOnce sync.Once
+
 // PandoraConnector for connect to pandora
 type PandoraConnector interface {
 	Register(metrics common.Meter)
@@ -28,29 +32,55 @@ type PandoraConnector interface {
 		params []models.StateSelector,
 		opts *models.Options,
 	) (newParams []models.StateSelector, err error)
+	StartStream(
+		ctx context.Context,
+		client string,
+		tester models.Tester,
+		params []models.StateSelector,
+		stream models.Stream,
+		opts *models.Options,
+	) (err error)
+	SubscribeOnProgress(ctx context.Context, ch chan<- *models.StressProgress)
+	SubscribeOnCheckpoints(ctx context.Context, ch chan<- *models.Checkpoint)
 }
 
 type gunConfigurator interface {
 	SetTester(tester models.Tester)
 	SetClient(client string)
+	SetLogger(logger log.Logger)
 	GetGunConfig() gunConfig
 }
 
 type providerConfigurator interface {
 	SetParameters(params []models.StateSelector, opts *models.Options)
+	SetStream(stream models.Stream, opts *models.Options)
 	GetParams() (params []models.StateSelector)
+	GetStream() (stream models.Stream)
 	Conf() (conf *models.Test)
 }
 
 type connector struct {
-	gunConfigurator
-	providerConfigurator
-	logger        log.Logger
-	engineMetrics engine.Metrics
+	logger      log.Logger
+	meter       common.Meter
+	progress    *common.Broadcaster[models.StressProgress]
+	checkpoints *common.Broadcaster[models.Checkpoint]
+}
+
+func (c *connector) SubscribeOnProgress(ctx context.Context, ch chan<- *models.StressProgress) {
+	c.progress.Subscribe(ctx, ch)
+}
+
+func (c *connector) SubscribeOnCheckpoints(ctx context.Context, ch chan<- *models.Checkpoint) {
+	c.checkpoints.Subscribe(ctx, ch)
 }
 
 func (c *connector) Register(metrics common.Meter) {
-	register.Aggregator(s3Aggregator, News3Aggregator(c.logger, metrics))
+	c.meter = metrics
+	registerOnce.Do(registerPlugins)
+}
+
+func registerPlugins() {
+	RegisterAggregator()
 
 	register.Limiter("line", schedule.NewLineConf)
 	register.Limiter("const", schedule.NewConstConf)
@@ -62,10 +92,14 @@ func (c *connector) Register(metrics common.Meter) {
 	pluginconfig.AddHooks()
 
 	// Custom imports. Integrate your custom types into configuration system.
-	RegisterProvider(c.providerConfigurator)
+	RegisterProvider()
 
 	// Register gun
-	RegisterGun(c.gunConfigurator)
+	RegisterGun()
+
+	zapLogger := newLogger()
+	zap.ReplaceGlobals(zapLogger)
+	zap.RedirectStdLog(zapLogger)
 }
 
 // Start stress test with pandora
@@ -76,26 +110,64 @@ func (c *connector) Start(
 	params []models.StateSelector,
 	opts *models.Options,
 ) (newParams []models.StateSelector, err error) {
-	c.providerConfigurator.SetParameters(params, opts)
-	c.gunConfigurator.SetClient(client)
-	c.gunConfigurator.SetTester(tester)
+	provConf := newProvConfig()
+	provConf.SetParameters(params, opts)
+	if err = c.start(ctx, client, tester, provConf, opts); err != nil {
+		return
+	}
+	return params, nil
+}
+
+// StartStream stress test with pandora, params are shot first, then ammo is generated lazily by stream
+func (c *connector) StartStream(
+	ctx context.Context,
+	client string,
+	tester models.Tester,
+	params []models.StateSelector,
+	stream models.Stream,
+	opts *models.Options,
+) (err error) {
+	provConf := newProvConfig()
+	provConf.SetParameters(params, opts)
+	provConf.SetStream(stream, opts)
+	return c.start(ctx, client, tester, provConf, opts)
+}
 
-	cancelReport := startReport(c.engineMetrics)
+func (c *connector) start(
+	ctx context.Context,
+	client string,
+	tester models.Tester,
+	provConf providerConfigurator,
+	opts *models.Options,
+) (err error) {
+	// every run has own plugins configuration, so stress loads can run concurrently
+	gunConf := newGunConf()
+	gunConf.SetClient(client)
+	gunConf.SetTester(tester)
+	gunConf.SetLogger(c.logger)
+	run := newStressRun(c.meter)
 
-	zapLogger := newLogger()
-	zap.ReplaceGlobals(zapLogger)
-	zap.RedirectStdLog(zapLogger)
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if err = run.abort.reset(opts.Conf.StressLoad.Abort, cancel); err != nil {
+		return
+	}
+
+	defer c.reportStop(run, opts)
 
 	var conf *engine.Config
-	conf, err = initConfig(*opts.Conf.StressLoad)
+	conf, err = initConfig(*opts.Conf.StressLoad, gunConf, provConf, run)
 	if err != nil {
 		return
 	}
 
-	pandora := engine.New(zapLogger, c.engineMetrics, *conf)
+	cancelReport := c.startReport(run, opts)
+	defer close(cancelReport)
+	cancelCheckpoints := c.startCheckpoints(run, opts)
+	defer close(cancelCheckpoints)
 
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
+	pandora := engine.New(zap.L(), run.engineMetrics, *conf)
+	run.stats.reset()
 
 	errs := make(chan error)
 	go runEngine(ctx, pandora, errs)
@@ -103,6 +175,12 @@ func (c *connector) Start(
 	// waiting for signal or error message from engine
 exit:
 	for err = range errs {
+		if reason, after := run.abort.aborted(); reason != "" {
+			pandora.Wait()
+			err = common.ErrStressAborted(reason)
+			c.logger.WithError(err).WithField("after", after.String()).Warn("engine run aborted")
+			return
+		}
 		switch err {
 		case nil:
 			c.logger.Info("Pandora engine successfully finished it's work")
@@ -114,6 +192,7 @@ exit:
 					"engine run failed. Awaiting started tasks. error: %s, timeout: %d",
 					err, awaitTimeout),
 			)
+			run.stop(fmt.Sprintf("engine failed: %s", err))
 			cancel()
 			time.AfterFunc(awaitTimeout, func() {
 				c.logger.Error(fmt.Errorf("engine tasks timeout exceeded"))
@@ -124,18 +203,38 @@ exit:
 		}
 	}
 	c.logger.Info("Engine run successfully finished")
-	close(cancelReport)
-	return params, nil
+
+	// check service level objectives of stress load
+	if err = opts.Conf.StressLoad.CheckThresholds(run.stats.summary()); err != nil {
+		c.logger.WithError(err).Warn("stress load thresholds breached")
+	}
+	return
+}
+
+// reportStop log and publish why shooting stopped
+func (c *connector) reportStop(run *stressRun, opts *models.Options) {
+	reason, after := run.stopReason()
+	logger := c.logger.WithField("scenario", opts.Conf.Name).WithField("id", opts.LaunchID)
+	dropped := run.stats.droppedCount()
+	if dropped > 0 {
+		logger.WithField("dropped", dropped).Warn("aggregator dropped samples")
+	}
+	logger.WithField("reason", reason).WithField("after", after.String()).Info("shooting stopped")
+	opts.StopReason = reason
+	c.progress.Publish(&models.StressProgress{
+		LaunchID:   opts.LaunchID,
+		Scenario:   opts.Conf.Name,
+		Errors:     run.stats.errorsCount(),
+		Dropped:    dropped,
+		StopReason: reason,
+	})
 }
 
-// NewConnector construct and register pandora instance
+// NewConnector construct pandora connector, it is safe to construct many connectors
 func NewConnector(logger log.Logger) PandoraConnector {
-	// CreateLottery engine metrics
-	m := newEngineMetrics()
 	return &connector{
-		logger:               logger,
-		providerConfigurator: newProvConfig(),
-		gunConfigurator:      newGunConf(),
-		engineMetrics:        m,
+		logger:      logger,
+		progress:    common.NewBroadcaster[models.StressProgress](),
+		checkpoints: common.NewBroadcaster[models.Checkpoint](),
 	}
 }
@@ -1,22 +1,34 @@
 package pandoraconnector
 
 import (
-	"fmt"
+	"time"
 
 	"github.com/yandex/pandora/core"
 	"github.com/yandex/pandora/core/register"
 
 	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/log"
 	"github.com/lueurxax/e2e/pkg/models"
 )
 
 // S3Gun is gun name for test s3
 const S3Gun = "s3_gun"
 
+const (
+	successCode = 200
+	failureCode = 500
+)
+
 // gunConfig config of s3 gun
 type gunConfig struct {
 	tester models.Tester
 	client string
+	logger log.Logger
+}
+
+// gunPluginConfig per run config of gun plugin
+type gunPluginConfig struct {
+	Configurator gunConfigurator `config:"configurator" validate:"required"`
 }
 
 // Gun is S3Gun structure
@@ -41,6 +53,21 @@ func (g *Gun) Shoot(ammo core.Ammo) {
 		g.Log.Error("invalid structure of ammo")
 	}
 	g.shoot(customAmmo)
+	g.think(customAmmo)
+}
+
+// think wait think time of virtual user in closed model
+func (g *Gun) think(ammo *Ammo) {
+	load := ammo.Conf.StressLoad
+	if !load.IsClosed() || load.ThinkTime <= 0 {
+		return
+	}
+	timer := time.NewTimer(load.ThinkTime)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-g.Ctx.Done():
+	}
 }
 
 func (g *Gun) shoot(ammo *Ammo) {
@@ -57,17 +84,18 @@ func (g *Gun) shoot(ammo *Ammo) {
 	})
 
 	if err != nil {
-		fmt.Printf("FATAL: %s", err)
-		code = 500
+		// failing target fails thousands of shots per second, they are counted in errors of stress progress
+		conf.logger.WithError(err).WithField("tester", conf.tester.MethodName()).Debug("shot failed")
+		code = failureCode
 		return
 	}
-	code = 200
+	code = successCode
 }
 
-// RegisterGun construct new gun
-func RegisterGun(configurator gunConfigurator) {
-	register.Gun(S3Gun, func() core.Gun {
-		return &Gun{configurator: configurator}
+// RegisterGun register gun plugin, tester and client are configured per run
+func RegisterGun() {
+	register.Gun(S3Gun, func(conf gunPluginConfig) core.Gun {
+		return &Gun{configurator: conf.Configurator}
 	})
 }
 
@@ -79,6 +107,10 @@ func (g *gunConfManager) SetClient(client string) {
 	g.config.client = client
 }
 
+func (g *gunConfManager) SetLogger(logger log.Logger) {
+	g.config.logger = logger
+}
+
 func (g *gunConfManager) SetTester(tester models.Tester) {
 	g.config.tester = tester
 }
@@ -2,12 +2,13 @@ package pandoraconnector
 
 import (
 	"context"
-	"fmt"
 	"time"
 
 	"github.com/yandex/pandora/core/engine"
 	"github.com/yandex/pandora/lib/monitoring"
 	"go.uber.org/zap"
+
+	"github.com/lueurxax/e2e/pkg/models"
 )
 
 func runEngine(ctx context.Context, engine *engine.Engine, errs chan error) {
@@ -16,16 +17,20 @@ func runEngine(ctx context.Context, engine *engine.Engine, errs chan error) {
 	errs <- engine.Run(ctx)
 }
 
+// newEngineMetrics construct metrics of one run, counters aren't published to expvar,
+// because expvar names are global and run can be started many times
 func newEngineMetrics() engine.Metrics {
 	return engine.Metrics{
-		Request:        monitoring.NewCounter("engine_Requests"),
-		Response:       monitoring.NewCounter("engine_Responses"),
-		InstanceStart:  monitoring.NewCounter("engine_UsersStarted"),
-		InstanceFinish: monitoring.NewCounter("engine_UsersFinished"),
+		Request:        &monitoring.Counter{},
+		Response:       &monitoring.Counter{},
+		InstanceStart:  &monitoring.Counter{},
+		InstanceFinish: &monitoring.Counter{},
 	}
 }
 
-func startReport(m engine.Metrics) (cancel chan struct{}) {
+func (c *connector) startReport(run *stressRun, opts *models.Options) (cancel chan struct{}) {
+	m := run.engineMetrics
+	logger := c.logger.WithField("scenario", opts.Conf.Name).WithField("id", opts.LaunchID)
 	requests := m.Request.Get()
 	responses := m.Response.Get()
 	cancel = make(chan struct{})
@@ -34,24 +39,36 @@ func startReport(m engine.Metrics) (cancel chan struct{}) {
 		// TODO(skipor): there is no guarantee, that we will run exactly after 1 second.
 		// So, when we get 1 sec +-10ms, we getting 990-1010 calculate intervals and +-2% RPS in reports.
 		// Consider using rcrowley/go-metrics.Meter.
-	exit:
+		ticker := time.NewTicker(1 * time.Second)
+		defer ticker.Stop()
 		for {
 			select {
-			case <-time.NewTicker(1 * time.Second).C:
+			case <-ticker.C:
 				requestsNew = m.Request.Get()
 				responsesNew = m.Response.Get()
-				rps := responsesNew - responses
-				reqps := requestsNew - requests
-				activeUsers := m.InstanceStart.Get() - m.InstanceFinish.Get()
-				activeRequests := requestsNew - responsesNew
-				fmt.Printf(
-					"[ENGINE] %d resp/s; %d req/s; %d users; %d active\n",
-					rps, reqps, activeUsers, activeRequests)
+				progress := &models.StressProgress{
+					LaunchID:           opts.LaunchID,
+					Scenario:           opts.Conf.Name,
+					ResponsesPerSecond: int(responsesNew - responses),
+					RequestsPerSecond:  int(requestsNew - requests),
+					ActiveUsers:        int(m.InstanceStart.Get() - m.InstanceFinish.Get()),
+					ActiveRequests:     int(requestsNew - responsesNew),
+					Errors:             run.stats.errorsCount(),
+					Dropped:            run.stats.droppedCount(),
+				}
+				logger.WithField("rps", progress.ResponsesPerSecond).
+					WithField("reqps", progress.RequestsPerSecond).
+					WithField("users", progress.ActiveUsers).
+					WithField("active", progress.ActiveRequests).
+					WithField("errors", progress.Errors).
+					WithField("dropped", progress.Dropped).
+					Info("engine progress")
+				c.progress.Publish(progress)
 
 				requests = requestsNew
 				responses = responsesNew
 			case <-cancel:
-				break exit
+				return
 			}
 		}
 	}(cancel)
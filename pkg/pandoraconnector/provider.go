@@ -2,6 +2,7 @@ package pandoraconnector
 
 import (
 	"context"
+	"fmt"
 
 	"github.com/yandex/pandora/core"
 	pandoraprov "github.com/yandex/pandora/core/provider"
@@ -39,6 +40,8 @@ type provider struct {
 	pandoraprov.AmmoQueue
 	*core.ProviderDeps
 	providerConfigurator
+	recorder stopRecorder
+	source   ammoSource
 }
 
 // Run starts provider routine of ammo  generation.
@@ -51,7 +54,27 @@ func (p *provider) Run(ctx context.Context, deps core.ProviderDeps) (err error)
 	p.ProviderDeps = &deps
 	p.Log.Info("run provider")
 	defer close(p.OutQueue)
-	for i, param := range p.providerConfigurator.GetParams() {
+	source, err := newAmmoSource(p.providerConfigurator)
+	if err != nil {
+		return
+	}
+	p.source = source
+	defer source.close()
+	for i := 0; ; i++ {
+		param, ok, err := source.next(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				p.Log.Debug("Provider run context is Done", zap.Int("decoded", i))
+				return nil
+			}
+			return err
+		}
+		if !ok {
+			// closed queue stops instances, so engine finishes without waiting for schedule end
+			p.recorder.stop(fmt.Sprintf("ammo exhausted after %d shots", i))
+			p.Log.Info("ammo exhausted", zap.Int("shots", i))
+			return nil
+		}
 		select {
 		case p.OutQueue <- &Ammo{
 			Params: param,
@@ -62,19 +85,29 @@ func (p *provider) Run(ctx context.Context, deps core.ProviderDeps) (err error)
 			return nil
 		}
 	}
-	<-ctx.Done()
-	return
 }
 
-// RegisterProvider register new s3 provider
-func RegisterProvider(
-	providerConfigurator providerConfigurator,
-) {
-	register.Provider(S3Provider, func() core.Provider {
+// Release notifies that ammo usage is finished, slot of streamed state or cycled state can be shot again
+func (p *provider) Release(ammo core.Ammo) {
+	if customAmmo, ok := ammo.(*Ammo); ok && p.source != nil {
+		p.source.release(customAmmo.Params)
+	}
+}
+
+// providerPluginConfig per run config of provider plugin
+type providerPluginConfig struct {
+	Configurator providerConfigurator `config:"configurator" validate:"required"`
+	Recorder     stopRecorder         `config:"recorder" validate:"required"`
+}
+
+// RegisterProvider register provider plugin, ammo is configured per run
+func RegisterProvider() {
+	register.Provider(S3Provider, func(conf providerPluginConfig) core.Provider {
 		newAmmo := func() core.Ammo { return map[string]interface{}{} }
 		p := &provider{
 			AmmoQueue:            *pandoraprov.NewAmmoQueue(newAmmo, pandoraprov.DefaultAmmoQueueConfig()),
-			providerConfigurator: providerConfigurator,
+			providerConfigurator: conf.Configurator,
+			recorder:             conf.Recorder,
 		}
 		return p
 	})
@@ -82,6 +115,7 @@ func RegisterProvider(
 
 type provConfigManager struct {
 	params []models.StateSelector
+	stream models.Stream
 	opts   *models.Options
 }
 
@@ -94,10 +128,19 @@ func (p *provConfigManager) SetParameters(params []models.StateSelector, opts *m
 	p.opts = opts
 }
 
+func (p *provConfigManager) SetStream(stream models.Stream, opts *models.Options) {
+	p.stream = stream
+	p.opts = opts
+}
+
 func (p *provConfigManager) GetParams() (params []models.StateSelector) {
 	return p.params
 }
 
+func (p *provConfigManager) GetStream() (stream models.Stream) {
+	return p.stream
+}
+
 func (p *provConfigManager) Conf() (conf *models.Test) {
 	return p.opts.Conf
 }
@@ -0,0 +1,85 @@
+package pandoraconnector
+
+import (
+	"sync"
+	"time"
+
+	"github.com/yandex/pandora/core/engine"
+
+	"github.com/lueurxax/e2e/common"
+)
+
+// sampleCollector handle samples reported to aggregator
+type sampleCollector interface {
+	collect(data *common.RequestData)
+	// drop count sample dropped by aggregator, it is called by guns, so it must be cheap
+	drop()
+}
+
+// stopRecorder record why shooting stopped before schedule end
+type stopRecorder interface {
+	stop(reason string)
+}
+
+// stressRunner state of run for engine plugins
+type stressRunner interface {
+	sampleCollector
+	stopRecorder
+}
+
+// scheduleFinished stop reason of run without early stop
+const scheduleFinished = "schedule finished"
+
+// stressRun state of one stress run shared by engine plugins through pandora config
+type stressRun struct {
+	meter         common.Meter
+	stats         *stats
+	abort         *abortWatcher
+	engineMetrics engine.Metrics
+
+	mu      sync.Mutex
+	started time.Time
+	reason  string
+}
+
+// stop record first reason of stop
+func (r *stressRun) stop(reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.reason == "" {
+		r.reason = reason
+	}
+}
+
+// stopReason return why shooting stopped and when, must be called after engine finish
+func (r *stressRun) stopReason() (reason string, after time.Duration) {
+	if reason, after = r.abort.aborted(); reason != "" {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.reason != "" {
+		return r.reason, time.Since(r.started)
+	}
+	return scheduleFinished, time.Since(r.started)
+}
+
+func (r *stressRun) collect(data *common.RequestData) {
+	r.stats.add(data)
+	r.abort.add(data)
+	r.meter.AddRequest(data)
+}
+
+func (r *stressRun) drop() {
+	r.stats.drop()
+}
+
+func newStressRun(meter common.Meter) *stressRun {
+	return &stressRun{
+		meter:         meter,
+		stats:         newStats(),
+		abort:         newAbortWatcher(),
+		engineMetrics: newEngineMetrics(),
+		started:       time.Now(),
+	}
+}
@@ -0,0 +1,200 @@
+package pandoraconnector
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/models"
+)
+
+// ammoSource produce params of shots for provider
+type ammoSource interface {
+	// next return params of next shot, ok false means that ammo is finished
+	next(ctx context.Context) (param models.StateSelector, ok bool, err error)
+	// release return params of finished shot
+	release(param models.StateSelector)
+	close()
+}
+
+func newAmmoSource(conf providerConfigurator) (ammoSource, error) {
+	load := conf.Conf().StressLoad
+	policy := load.AmmoExhausted()
+	stream := conf.GetStream()
+	if stream == nil {
+		return newSliceSource(conf.GetParams(), policy == common.AmmoCycle), nil
+	}
+	params := newSliceSource(conf.GetParams(), false)
+	source := &streamSource{stream: stream, policy: policy}
+	if policy == common.AmmoFinish {
+		source.limit = load.GetShootCount()
+	}
+	if load.Streaming != nil && load.Streaming.DataFile != "" {
+		file, err := os.Open(load.Streaming.DataFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "open ammo data file")
+		}
+		source.file = file
+		source.records = bufio.NewScanner(file)
+	}
+	if len(params.params) == 0 {
+		return source, nil
+	}
+	// prepared params are shot first, then ammo is generated
+	params.fallback = source
+	return params, nil
+}
+
+// sliceSource ammo from materialized state
+type sliceSource struct {
+	params   []models.StateSelector
+	index    int
+	fallback ammoSource
+	// free indexes of cycled params, which aren't shot now, so concurrent shots never share state
+	free    chan int
+	indexes map[models.StateSelector]int
+}
+
+// newSliceSource construct source of prepared params, cycled params are shot again after release
+func newSliceSource(params []models.StateSelector, cycle bool) *sliceSource {
+	s := &sliceSource{params: params}
+	if !cycle || len(params) == 0 {
+		return s
+	}
+	s.free = make(chan int, len(params))
+	s.indexes = make(map[models.StateSelector]int, len(params))
+	for i, param := range params {
+		s.free <- i
+		s.indexes[param] = i
+	}
+	return s
+}
+
+func (s *sliceSource) next(ctx context.Context) (param models.StateSelector, ok bool, err error) {
+	if s.free != nil {
+		// wait for release of prepared state, count of concurrent shots is bounded by count of params
+		select {
+		case i := <-s.free:
+			return s.params[i], true, nil
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+	}
+	if s.index >= len(s.params) {
+		if s.fallback != nil {
+			return s.fallback.next(ctx)
+		}
+		return nil, false, nil
+	}
+	param = s.params[s.index]
+	s.index++
+	return param, true, nil
+}
+
+func (s *sliceSource) release(param models.StateSelector) {
+	if s.free != nil {
+		if i, ok := s.indexes[param]; ok {
+			s.free <- i
+		}
+		return
+	}
+	if s.fallback != nil {
+		s.fallback.release(param)
+	}
+}
+
+func (s *sliceSource) close() {
+	if s.fallback != nil {
+		s.fallback.close()
+	}
+}
+
+// streamSource ammo generated lazily by stream, records of data file override generated values
+type streamSource struct {
+	stream  models.Stream
+	policy  string
+	limit   int
+	count   int
+	file    *os.File
+	records *bufio.Scanner
+	read    int
+	// line number of last scanned line of data file
+	line int
+}
+
+func (s *streamSource) next(ctx context.Context) (param models.StateSelector, ok bool, err error) {
+	if s.limit > 0 && s.count >= s.limit {
+		return nil, false, nil
+	}
+	var record map[string]interface{}
+	if record, ok, err = s.nextRecord(); !ok || err != nil {
+		return
+	}
+	if param, err = s.stream.Next(ctx, record); err != nil {
+		if record != nil {
+			err = errors.Wrapf(err, "ammo data file line %d", s.line)
+		}
+		return
+	}
+	s.count++
+	return param, true, nil
+}
+
+func (s *streamSource) nextRecord() (record map[string]interface{}, ok bool, err error) {
+	if s.records == nil {
+		return nil, true, nil
+	}
+	for {
+		for s.records.Scan() {
+			s.line++
+			line := bytes.TrimSpace(s.records.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			decoder := json.NewDecoder(bytes.NewReader(line))
+			decoder.UseNumber()
+			if err = decoder.Decode(&record); err != nil {
+				return nil, false, errors.Wrapf(err, "decode ammo data file line %d", s.line)
+			}
+			common.NormalizeNumbers(record)
+			s.read++
+			return record, true, nil
+		}
+		if err = s.records.Err(); err != nil {
+			return nil, false, err
+		}
+		switch s.policy {
+		case common.AmmoCycle:
+			// read data file from the beginning, empty file can't be cycled
+			if s.read == 0 {
+				return nil, false, nil
+			}
+			if _, err = s.file.Seek(0, io.SeekStart); err != nil {
+				return nil, false, err
+			}
+			s.records, s.read, s.line = bufio.NewScanner(s.file), 0, 0
+		case common.AmmoGenerate:
+			// data file is finished, only generators are used
+			s.records = nil
+			return nil, true, nil
+		default:
+			return nil, false, nil
+		}
+	}
+}
+
+func (s *streamSource) release(param models.StateSelector) {
+	s.stream.Release(param)
+}
+
+func (s *streamSource) close() {
+	if s.file != nil {
+		_ = s.file.Close()
+	}
+}
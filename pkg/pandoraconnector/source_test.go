@@ -0,0 +1,87 @@
+package pandoraconnector
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lueurxax/e2e/pkg/models"
+)
+
+type testSelector struct {
+	index int
+}
+
+func (s *testSelector) Index() int {
+	return s.index
+}
+
+func testParams(count int) []models.StateSelector {
+	params := make([]models.StateSelector, count)
+	for i := range params {
+		params[i] = &testSelector{index: i}
+	}
+	return params
+}
+
+func TestSliceSourceFinish(t *testing.T) {
+	source := newSliceSource(testParams(2), false)
+	for i := 0; i < 2; i++ {
+		param, ok, err := source.next(context.Background())
+		if err != nil || !ok || param.Index() != i {
+			t.Fatalf("unexpected shot %d: %v %v %v", i, param, ok, err)
+		}
+	}
+	if _, ok, err := source.next(context.Background()); ok || err != nil {
+		t.Fatalf("expected exhausted ammo, got %v %v", ok, err)
+	}
+}
+
+func TestSliceSourceCycleWaitRelease(t *testing.T) {
+	source := newSliceSource(testParams(2), true)
+	first, _, _ := source.next(context.Background())
+	second, _, _ := source.next(context.Background())
+	if first == second {
+		t.Fatal("the same state is shot twice without release")
+	}
+
+	// all states are shot now
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, _, err := source.next(ctx); err == nil {
+		t.Fatal("state of running shot is shot again")
+	}
+
+	source.release(second)
+	param, ok, err := source.next(context.Background())
+	if err != nil || !ok || param != second {
+		t.Fatalf("expected released state, got %v %v %v", param, ok, err)
+	}
+}
+
+func TestSliceSourceCycleConcurrentShots(t *testing.T) {
+	const shots = 1000
+	params := testParams(4)
+	source := newSliceSource(params, true)
+	busy := make([]int32, len(params))
+	var wg sync.WaitGroup
+	for i := 0; i < shots; i++ {
+		param, ok, err := source.next(context.Background())
+		if err != nil || !ok {
+			t.Fatalf("unexpected shot %d: %v %v", i, ok, err)
+		}
+		wg.Add(1)
+		go func(param models.StateSelector) {
+			defer wg.Done()
+			if !atomic.CompareAndSwapInt32(&busy[param.Index()], 0, 1) {
+				t.Errorf("state %d is shared by concurrent shots", param.Index())
+				return
+			}
+			atomic.StoreInt32(&busy[param.Index()], 0)
+			source.release(param)
+		}(param)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,97 @@
+package pandoraconnector
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lueurxax/e2e/common"
+)
+
+// stats collect samples of stress run for thresholds evaluation and checkpoints of soak load,
+// latencies are kept in histograms, so memory doesn't grow with duration of run.
+// Samples dropped by aggregator are counted apart, result of their requests is unknown.
+type stats struct {
+	mu        sync.Mutex
+	started   time.Time
+	latencies *common.Histogram
+	errors    int
+	dropped   int
+
+	windowStarted   time.Time
+	windowLatencies *common.Histogram
+	windowErrors    int
+	windowDropped   int
+}
+
+func (s *stats) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.started = time.Now()
+	s.latencies = common.NewHistogram()
+	s.errors, s.dropped = 0, 0
+	s.windowStarted = s.started
+	s.windowLatencies = common.NewHistogram()
+	s.windowErrors, s.windowDropped = 0, 0
+}
+
+func (s *stats) add(data *common.RequestData) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.latencies.Add(data.Latency)
+	s.windowLatencies.Add(data.Latency)
+	if data.Code != successCode {
+		s.errors++
+		s.windowErrors++
+	}
+}
+
+func (s *stats) drop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dropped++
+	s.windowDropped++
+}
+
+func (s *stats) errorsCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.errors
+}
+
+func (s *stats) droppedCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+func (s *stats) summary() *common.Summary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	latencies := *s.latencies
+	summary := common.NewSummary(&latencies, s.errors, time.Since(s.started))
+	summary.Dropped = s.dropped
+	return summary
+}
+
+// checkpoint return summary of samples collected since previous checkpoint and start new window
+func (s *stats) checkpoint() *common.Summary {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	summary := common.NewSummary(s.windowLatencies, s.windowErrors, now.Sub(s.windowStarted))
+	summary.Dropped = s.windowDropped
+	s.windowStarted = now
+	s.windowLatencies = common.NewHistogram()
+	s.windowErrors, s.windowDropped = 0, 0
+	return summary
+}
+
+func newStats() *stats {
+	now := time.Now()
+	return &stats{
+		started:         now,
+		latencies:       common.NewHistogram(),
+		windowStarted:   now,
+		windowLatencies: common.NewHistogram(),
+	}
+}
@@ -0,0 +1,135 @@
+package processor
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/models"
+	"github.com/lueurxax/e2e/pkg/secrets"
+	"github.com/lueurxax/e2e/pkg/testerspool"
+)
+
+// NewPlan resolve execution plan of prepared scenario with testers of pool, no request is sent.
+// Stage gets global params with keys generated by $random and $increment, params of stage
+// and returned fields of previous stages.
+func NewPlan(scenario models.Scenario, testers testerspool.TestersPool) *models.ScenarioPlan {
+	conf := scenario.Config
+	plan := &models.ScenarioPlan{
+		Scenario:   scenario.Name,
+		ShootCount: 1,
+		Params:     planParams(conf),
+		Stages:     make([]models.StagePlan, 0, len(conf.BeforeTest)+3),
+		Issues:     make([]string, 0),
+	}
+	if load := conf.StressLoad; load != nil {
+		plan.StressLoad = true
+		plan.Streaming = load.IsStreaming()
+		plan.ShootCount = load.GetShootCount()
+	}
+	if conf.Repeat > 1 {
+		plan.ShootCount = conf.Repeat
+	}
+
+	available := make(map[string]struct{}, len(conf.Params))
+	for _, param := range plan.Params {
+		available[param.Name] = struct{}{}
+	}
+	add := func(name string, stageConf *models.TestStage, stage *models.Stage) {
+		stagePlan := planStage(name, stageConf, stage, available, testers, scenario.Name, plan)
+		plan.Stages = append(plan.Stages, stagePlan)
+		for _, field := range stagePlan.Returned {
+			available[field] = struct{}{}
+		}
+	}
+	for i := range conf.BeforeTest {
+		add(fmt.Sprintf("before_test[%d]", i), &conf.BeforeTest[i], &scenario.BeforeTest[i])
+	}
+	add("action", &conf.Action, scenario.Action)
+	// check and after test are optional, stage without tester isn't run
+	if conf.Check.Name != "" {
+		add("check", &conf.Check, scenario.Check)
+	}
+	if conf.AfterTest.Name != "" {
+		add("after_test", &conf.AfterTest, scenario.AfterTest)
+	}
+	return plan
+}
+
+func planStage(
+	name string,
+	conf *models.TestStage,
+	stage *models.Stage,
+	available map[string]struct{},
+	testers testerspool.TestersPool,
+	scenarioName string,
+	plan *models.ScenarioPlan,
+) models.StagePlan {
+	stagePlan := models.StagePlan{
+		Stage:    name,
+		Tester:   conf.Name,
+		Client:   conf.Client,
+		Params:   sortedKeys(conf.Params),
+		Required: make([]string, 0),
+		Missing:  make([]string, 0),
+		Returned: make([]string, 0),
+	}
+	if stage != nil {
+		stagePlan.RequestsCount = stage.RequestsCount
+		stagePlan.WantError = stage.WantError
+		stagePlan.Error = stage.Error
+	}
+	tester, err := testers.Get(conf.Name)
+	if err != nil {
+		plan.Issues = append(plan.Issues, fmt.Sprintf("%s: %s", name, err))
+		return stagePlan
+	}
+	stagePlan.Required = append(stagePlan.Required, tester.RequiredFields()...)
+	stagePlan.Returned = append(stagePlan.Returned, tester.ReturnedFields()...)
+	for _, field := range stagePlan.Required {
+		_, ok := available[field]
+		if _, own := conf.Params[field]; !ok && !own {
+			stagePlan.Missing = append(stagePlan.Missing, field)
+			plan.Issues = append(plan.Issues, fmt.Sprintf(
+				"%s: %s", name, common.ErrRequiredFieldDidntSet(conf.Name, scenarioName, field),
+			))
+		}
+	}
+	return stagePlan
+}
+
+// planParams global params after Prepare sorted by name, values of secrets are masked
+func planParams(conf *models.Test) []models.PlanParam {
+	params := make([]models.PlanParam, 0, len(conf.Params))
+	for key, value := range conf.InitState.GlobalParams {
+		params = append(params, models.PlanParam{Name: key, Value: planValue(value)})
+	}
+	for _, key := range conf.InitState.Random {
+		params = append(params, models.PlanParam{Name: key, Value: "$random"})
+	}
+	for _, key := range conf.InitState.Increment {
+		params = append(params, models.PlanParam{Name: key, Value: "$increment"})
+	}
+	sort.Slice(params, func(i, j int) bool {
+		return params[i].Name < params[j].Name
+	})
+	return params
+}
+
+func planValue(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return secrets.Redact(fmt.Sprint(value))
+	}
+	return secrets.Redact(string(data))
+}
+
+func sortedKeys(params map[string]interface{}) []string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
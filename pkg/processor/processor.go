@@ -6,6 +6,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/agent"
 	"github.com/lueurxax/e2e/pkg/log"
 	"github.com/lueurxax/e2e/pkg/models"
 	"github.com/lueurxax/e2e/pkg/pandoraconnector"
@@ -16,7 +17,12 @@ import (
 // Processor interface process tests
 type Processor interface {
 	ValidateScenario(scenario models.Scenario) error
-	Run(ctx context.Context, scenario models.Scenario, launchID string) (err error)
+	Plan(scenario models.Scenario) (plan *models.ScenarioPlan)
+	Testers() (testers []models.TesterMeta)
+	// Run test scenario, stop reason of stress load is empty for scenarios without stress load
+	Run(ctx context.Context, scenario models.Scenario, launchID string) (stopReason string, err error)
+	SubscribeOnStressProgress(ctx context.Context, ch chan<- *models.StressProgress)
+	SubscribeOnCheckpoints(ctx context.Context, ch chan<- *models.Checkpoint)
 }
 
 type worker interface {
@@ -30,52 +36,71 @@ type worker interface {
 	) (newParams []models.StateSelector, err error)
 }
 
+type streamWorker interface {
+	StartStream(
+		ctx context.Context,
+		client string,
+		tester models.Tester,
+		params []models.StateSelector,
+		stream models.Stream,
+		opts *models.Options,
+	) (err error)
+}
+
+type progressSubscriber interface {
+	SubscribeOnProgress(ctx context.Context, ch chan<- *models.StressProgress)
+	SubscribeOnCheckpoints(ctx context.Context, ch chan<- *models.Checkpoint)
+}
+
 type processor struct {
-	state models.State
+	state    models.State
+	progress progressSubscriber
+	testers  testerspool.TestersPool
+	// streaming stress worker supports lazily generated ammo
+	streaming bool
 
 	stageProcessor StageProcessor
 	logger         log.Logger
 	metrics        common.Meter
 }
 
-// ValidateScenario map scenario params and validate current scenario params
+// ValidateScenario check testers of stages and flow of fields of prepared scenario,
+// all problems are reported at once as issues of plan
 func (p *processor) ValidateScenario(scenario models.Scenario) (err error) {
-	globalFields := make([]string, 0)
-	var fields []string
-	for key := range scenario.Config.Params {
-		globalFields = append(globalFields, key)
+	plan := p.Plan(scenario)
+	if len(plan.Issues) > 0 {
+		return common.ErrConfigIssues(plan.Issues)
 	}
+	return nil
+}
 
-	// validate before test params
-	for i := range scenario.Config.BeforeTest {
-		fields, err = p.stageProcessor.Validate(globalFields, &scenario.Config.BeforeTest[i], scenario.Name)
-		if err != nil {
-			return
-		}
-		globalFields = append(globalFields, fields...)
+// Plan resolve execution plan of scenario without requests
+func (p *processor) Plan(scenario models.Scenario) *models.ScenarioPlan {
+	plan := NewPlan(scenario, p.testers)
+	if load := scenario.Config.StressLoad; load != nil && !p.streaming &&
+		(load.IsStreaming() || load.AmmoExhausted() == common.AmmoGenerate) {
+		plan.Issues = append(plan.Issues, "stress load: stress worker doesn't support streaming ammo")
 	}
+	return plan
+}
 
-	// validate action params
-	fields, err = p.stageProcessor.Validate(globalFields, &scenario.Config.Action, scenario.Name)
-	if err != nil {
-		return
-	}
-	globalFields = append(globalFields, fields...)
+// Testers metadata of registered testers
+func (p *processor) Testers() []models.TesterMeta {
+	return testerspool.Describe(p.testers, p.state)
+}
 
-	// validate check params
-	fields, err = p.stageProcessor.Validate(globalFields, &scenario.Config.Check, scenario.Name)
-	if err != nil {
-		return
-	}
-	globalFields = append(globalFields, fields...)
+// SubscribeOnStressProgress subscribe on live progress of stress loads until context is done
+func (p *processor) SubscribeOnStressProgress(ctx context.Context, ch chan<- *models.StressProgress) {
+	p.progress.SubscribeOnProgress(ctx, ch)
+}
 
-	// validate after test params
-	_, err = p.stageProcessor.Validate(globalFields, &scenario.Config.AfterTest, scenario.Name)
-	return
+// SubscribeOnCheckpoints subscribe on checkpoints of soak loads until context is done
+func (p *processor) SubscribeOnCheckpoints(ctx context.Context, ch chan<- *models.Checkpoint) {
+	p.progress.SubscribeOnCheckpoints(ctx, ch)
 }
 
 // Run test scenario
-func (p *processor) Run(ctx context.Context, scenario models.Scenario, launchID string) (err error) {
+func (p *processor) Run(ctx context.Context, scenario models.Scenario, launchID string) (stopReason string, err error) {
 	p.logger.WithField("scenario", scenario.Name).WithField("id", launchID).Info("run")
 	p.metrics.NewLaunch(launchID)
 	stressLoad := false
@@ -84,18 +109,31 @@ func (p *processor) Run(ctx context.Context, scenario models.Scenario, launchID
 		p.metrics.NewStress(scenario.Name, *scenario.Config.StressLoad)
 		stressLoad = true
 		shootCount = scenario.Config.StressLoad.ShootCount
+		if scenario.Config.StressLoad.IsStreaming() {
+			// ammo of action is generated lazily, state keeps only one prepared slot
+			shootCount = 1
+		}
 	}
 	if scenario.Config.Repeat > 1 {
 		shootCount = scenario.Config.Repeat
 	}
 
+	opts := &models.Options{
+		Conf:     scenario.Config,
+		LaunchID: launchID,
+	}
+
 	// init scenario state
 	selectors := p.state.Reset(shootCount)
 	p.state.Prepare(scenario.Config.InitState)
 
 	// clean instance after tests
 	defer func(scenario models.Scenario, metr common.Meter) {
-		_, err2 := p.stageProcessor.Run(ctx, scenario.AfterTest, selectors, scenario.Config, false)
+		var err2 error
+		// after test is optional
+		if scenario.AfterTest.Tester != "" {
+			_, err2 = p.stageProcessor.Run(ctx, scenario.AfterTest, selectors, opts, false)
+		}
 		metr.Reset()
 		if err2 != nil {
 			p.logger.WithField("scenario", scenario.Name).WithError(err2).
@@ -106,7 +144,7 @@ func (p *processor) Run(ctx context.Context, scenario models.Scenario, launchID
 
 	// run before test
 	for i, stage := range scenario.BeforeTest {
-		newStates, err := p.stageProcessor.Run(ctx, &scenario.BeforeTest[i], selectors, scenario.Config, false)
+		newStates, err := p.stageProcessor.Run(ctx, &scenario.BeforeTest[i], selectors, opts, false)
 		if err != nil {
 			p.logger.WithField("scenario", scenario.Name).WithError(err).
 				Warn("failed run before test for scenario")
@@ -123,7 +161,8 @@ func (p *processor) Run(ctx context.Context, scenario models.Scenario, launchID
 
 	// run test actions
 	var newSelectors []models.StateSelector
-	newSelectors, err = p.stageProcessor.Run(ctx, scenario.Action, selectors, scenario.Config, stressLoad)
+	newSelectors, err = p.stageProcessor.Run(ctx, scenario.Action, selectors, opts, stressLoad)
+	stopReason = opts.StopReason
 	if err != nil {
 		p.logger.WithField("scenario", scenario.Name).WithError(err).
 			Warn("failed run action test for scenario")
@@ -136,21 +175,24 @@ func (p *processor) Run(ctx context.Context, scenario models.Scenario, launchID
 		p.state.MergeToState(newSelectors)
 	}
 
-	// run checks of this test
-	newSelectors, err = p.stageProcessor.Run(ctx, scenario.Check, newSelectors, scenario.Config, false)
+	// run checks of this test, check is optional
+	if scenario.Check.Tester == "" {
+		return stopReason, nil
+	}
+	newSelectors, err = p.stageProcessor.Run(ctx, scenario.Check, newSelectors, opts, false)
 	if (err != nil && !scenario.Check.WantError && err.Error() != scenario.Check.Error) ||
 		(err == nil && scenario.Check.WantError) {
 		// FIXME write real error
 		p.logger.WithField("scenario", scenario.Name).WithError(err).
 			Warn("failed run check for scenario")
-		return errors.Wrap(err, "on check")
+		return stopReason, errors.Wrap(err, "on check")
 	}
 	// merge state if it possible
 	if scenario.Check.RequestsCount == shootCount {
 		p.state.MergeToState(newSelectors)
 	}
 
-	return nil
+	return stopReason, nil
 }
 
 // NewProcessor construct new Processor
@@ -165,13 +207,49 @@ func NewProcessor(
 
 	pandora.Register(metrics)
 
-	proc = &processor{
-		state: state,
+	return newProcessor(state, testers, l, metrics, workerPoolSize, pandora, pandora), nil
+}
+
+// NewDistributedProcessor construct new Processor, which split stress loads across remote agents,
+// token is shared secret of agents
+func NewDistributedProcessor(
+	state models.State,
+	testers testerspool.TestersPool,
+	l log.Logger,
+	metrics common.Meter,
+	workerPoolSize int,
+	agents []string,
+	token string,
+) (proc Processor, err error) {
+	var coordinator agent.Coordinator
+	coordinator, err = agent.NewCoordinator(agents, token, state, nil, l.WithField("receiver", "coordinator"))
+	if err != nil {
+		return
+	}
+	coordinator.Register(metrics)
+
+	return newProcessor(state, testers, l, metrics, workerPoolSize, coordinator, coordinator), nil
+}
+
+func newProcessor(
+	state models.State,
+	testers testerspool.TestersPool,
+	l log.Logger,
+	metrics common.Meter,
+	workerPoolSize int,
+	stress worker,
+	progress progressSubscriber,
+) Processor {
+	_, streaming := stress.(streamWorker)
+	return &processor{
+		state:     state,
+		progress:  progress,
+		testers:   testers,
+		streaming: streaming,
 		stageProcessor: newStageProcessor(
-			state, testers, pandora, workerspool.NewPool(workerPoolSize), metrics, l,
+			state, testers, stress, workerspool.NewPool(workerPoolSize), metrics, l,
 		),
 		logger:  l,
 		metrics: metrics,
 	}
-	return
 }
@@ -0,0 +1,185 @@
+package processor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/log"
+	"github.com/lueurxax/e2e/pkg/models"
+	"github.com/lueurxax/e2e/pkg/testerspool"
+)
+
+const streamingIssue = "stress load: stress worker doesn't support streaming ammo"
+
+// batchWorker stress worker and progress subscriber without streaming support
+type batchWorker struct{}
+
+func (w *batchWorker) Register(common.Meter) {}
+
+func (w *batchWorker) Start(
+	context.Context, string, models.Tester, []models.StateSelector, *models.Options,
+) ([]models.StateSelector, error) {
+	return nil, nil
+}
+
+func (w *batchWorker) SubscribeOnProgress(context.Context, chan<- *models.StressProgress) {}
+
+func (w *batchWorker) SubscribeOnCheckpoints(context.Context, chan<- *models.Checkpoint) {}
+
+// streamingBatchWorker stress worker with streaming support
+type streamingBatchWorker struct {
+	batchWorker
+}
+
+func (w *streamingBatchWorker) StartStream(
+	context.Context, string, models.Tester, []models.StateSelector, models.Stream, *models.Options,
+) error {
+	return nil
+}
+
+func stressScenario(load common.StressLoad) models.Scenario {
+	return models.Scenario{
+		Name:   "stress",
+		Action: &models.Stage{},
+		Config: &models.Test{Name: "stress", StressLoad: &load},
+	}
+}
+
+func TestPlanStreamingAmmoOfStressWorker(t *testing.T) {
+	logger := log.NewLogger(logrus.New())
+	load := common.StressLoad{Duration: common.Duration(time.Second), From: 1, To: 1}
+	tests := []struct {
+		name  string
+		load  common.StressLoad
+		issue bool
+	}{
+		{name: "prepared ammo", load: load},
+		{name: "streaming ammo", load: func() common.StressLoad {
+			l := load
+			l.Streaming = &common.Streaming{}
+			return l
+		}(), issue: true},
+		{name: "generated ammo", load: func() common.StressLoad {
+			l := load
+			l.OnAmmoExhausted = common.AmmoGenerate
+			return l
+		}(), issue: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workers := map[bool]interface {
+				worker
+				progressSubscriber
+			}{false: &batchWorker{}, true: &streamingBatchWorker{}}
+			for streaming, stress := range workers {
+				p := newProcessor(nil, testerspool.NewTestersPool(nil), logger, nil, 1, stress, stress)
+				plan := p.Plan(stressScenario(tt.load))
+				var found bool
+				for _, issue := range plan.Issues {
+					found = found || strings.Contains(issue, streamingIssue)
+				}
+				if found != (tt.issue && !streaming) {
+					t.Fatalf("streaming worker %v: unexpected issues %v", streaming, plan.Issues)
+				}
+			}
+		})
+	}
+}
+
+// tester requires and returns fields of state
+type tester struct {
+	name               string
+	required, returned []string
+}
+
+func (t *tester) MethodName() string {
+	return t.name
+}
+
+func (t *tester) RequiredFields() []string {
+	return t.required
+}
+
+func (t *tester) ReturnedFields() []string {
+	return t.returned
+}
+
+func (t *tester) Run(context.Context, string, models.StateSelector, *models.Options) (models.StateSelector, error) {
+	return nil, nil
+}
+
+func TestPlanOptionalStages(t *testing.T) {
+	testers := testerspool.NewTestersPool([]models.Tester{
+		&tester{name: "Login", returned: []string{"token"}},
+		&tester{name: "GetUser", required: []string{"token"}},
+	})
+	tests := []struct {
+		name   string
+		conf   models.Test
+		stages []string
+		issues []string
+	}{
+		{
+			name:   "without check and after test",
+			conf:   models.Test{Name: "login", Action: models.TestStage{Name: "Login"}},
+			stages: []string{"action"},
+		},
+		{
+			name: "without after test",
+			conf: models.Test{
+				Name:   "user",
+				Action: models.TestStage{Name: "Login"},
+				Check:  models.TestStage{Name: "GetUser"},
+			},
+			stages: []string{"action", "check"},
+		},
+		{
+			name: "all stages",
+			conf: models.Test{
+				Name:       "user",
+				BeforeTest: []models.TestStage{{Name: "Login"}},
+				Action:     models.TestStage{Name: "GetUser"},
+				Check:      models.TestStage{Name: "GetUser"},
+				AfterTest:  models.TestStage{Name: "Logout"},
+			},
+			stages: []string{"before_test[0]", "action", "check", "after_test"},
+			issues: []string{"after_test: " + common.ErrUnknownMethod("Logout").Error()},
+		},
+		{
+			name:   "action is required",
+			conf:   models.Test{Name: "empty"},
+			stages: []string{"action"},
+			issues: []string{"action: " + common.ErrUnknownMethod("").Error()},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := tt.conf
+			conf.Prepare()
+			scenario := models.Scenario{
+				Name:       conf.Name,
+				Config:     &conf,
+				BeforeTest: make([]models.Stage, len(conf.BeforeTest)),
+				Action:     &models.Stage{Tester: conf.Action.Name},
+				Check:      &models.Stage{Tester: conf.Check.Name},
+				AfterTest:  &models.Stage{Tester: conf.AfterTest.Name},
+			}
+			plan := NewPlan(scenario, testers)
+			stages := make([]string, len(plan.Stages))
+			for i, stage := range plan.Stages {
+				stages[i] = stage.Stage
+			}
+			if strings.Join(stages, ",") != strings.Join(tt.stages, ",") {
+				t.Fatalf("expected stages %v, got %v", tt.stages, stages)
+			}
+			if strings.Join(plan.Issues, "\n") != strings.Join(tt.issues, "\n") {
+				t.Fatalf("expected issues %q, got %q", tt.issues, plan.Issues)
+			}
+		})
+	}
+}
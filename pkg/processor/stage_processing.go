@@ -14,7 +14,7 @@ type StageProcessor interface {
 	Validate(globalFields []string, stage *models.TestStage, name string) (resultFields []string, err error)
 	Run(
 		ctx context.Context,
-		test *models.Stage, state []models.StateSelector, config *models.Test, stressLoad bool,
+		test *models.Stage, state []models.StateSelector, opts *models.Options, stressLoad bool,
 	) (newParams []models.StateSelector, err error)
 }
 
@@ -48,19 +48,23 @@ func (s *stageProcessor) Run(
 	ctx context.Context,
 	stage *models.Stage,
 	scenarioState []models.StateSelector,
-	config *models.Test,
+	opts *models.Options,
 	stressLoad bool,
 ) (newParams []models.StateSelector, err error) {
-	state := s.state.AddToState(scenarioState, stage.Params...)
-	opts := &models.Options{
-		Conf: config,
-	}
-
 	var tester models.Tester
 	tester, err = s.testers.Get(stage.Tester)
 	if err != nil {
 		return
 	}
+	if stressLoad && opts.Conf.StressLoad.IsStreaming() {
+		return s.runStream(ctx, stage, scenarioState, nil, tester, opts)
+	}
+
+	state := s.state.AddToState(scenarioState, stage.Params...)
+	if stressLoad && opts.Conf.StressLoad.AmmoExhausted() == common.AmmoGenerate {
+		// prepared state is shot first, then fresh ammo is generated
+		return s.runStream(ctx, stage, scenarioState, state, tester, opts)
+	}
 	if stressLoad {
 		if newParams, err = s.pandora.Start(
 			ctx,
@@ -85,6 +89,41 @@ func (s *stageProcessor) Run(
 	return
 }
 
+// runStream run stress load with lazily generated ammo, scenario state is the base of every generated state,
+// prepared params are shot before generated ammo
+func (s *stageProcessor) runStream(
+	ctx context.Context,
+	stage *models.Stage,
+	scenarioState []models.StateSelector,
+	params []models.StateSelector,
+	tester models.Tester,
+	opts *models.Options,
+) (newParams []models.StateSelector, err error) {
+	streamer, ok := s.state.(models.StateStream)
+	if !ok {
+		return nil, common.ErrInvalidConfig("state doesn't support streaming ammo")
+	}
+	pandora, ok := s.pandora.(streamWorker)
+	if !ok {
+		return nil, common.ErrInvalidConfig("stress worker doesn't support streaming ammo")
+	}
+	var base models.StateSelector
+	if len(scenarioState) > 0 {
+		base = scenarioState[0]
+	}
+	// generated ammo continues counter of prepared ammo
+	stream := streamer.NewStream(
+		opts.Conf.StressLoad.Streaming.GetSlots(), len(params), opts.Conf.InitState, base, stage.Params...,
+	)
+	if err = pandora.StartStream(ctx, stage.Client, tester, params, stream, opts); err != nil {
+		return
+	}
+	if params != nil {
+		return params, nil
+	}
+	return scenarioState, nil
+}
+
 func (s *stageProcessor) getReturnedFields(stage *models.TestStage) (fields []string, err error) {
 	tester, err := s.testers.Get(stage.Name)
 	if err != nil {
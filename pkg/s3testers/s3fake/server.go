@@ -0,0 +1,360 @@
+// Package s3fake in-process fake of S3 compatible object storage for tests of S3 testers.
+// It supports path style requests of buckets, objects, listing and multipart uploads and doesn't check signatures.
+package s3fake
+
+import (
+	"bufio"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// streamingPayload prefix of x-amz-content-sha256 of aws-chunked payloads
+const streamingPayload = "STREAMING-"
+
+type object struct {
+	data     []byte
+	etag     string
+	modified time.Time
+}
+
+type upload struct {
+	bucket, key string
+	parts       map[int]*object
+}
+
+// Server fake of S3, it is http.Handler, use it with httptest.NewServer
+type Server struct {
+	mu      sync.Mutex
+	buckets map[string]map[string]*object
+	uploads map[string]*upload
+}
+
+// New construct empty fake of S3
+func New() *Server {
+	return &Server{
+		buckets: map[string]map[string]*object{},
+		uploads: map[string]*upload{},
+	}
+}
+
+// ServeHTTP route request by bucket, key, method and query
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	query := r.URL.Query()
+	// payload is read before lock, so uploads don't block each other
+	var payload *object
+	if r.Method == http.MethodPut && key != "" {
+		var err error
+		if payload, err = readObject(r); err != nil {
+			writeError(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+			return
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch {
+	case bucket == "":
+		writeError(w, http.StatusNotImplemented, "NotImplemented", "listing of buckets isn't supported")
+	case key == "":
+		s.serveBucket(w, r, bucket, query)
+	case query.Has("uploads") || query.Has("uploadId"):
+		s.serveUpload(w, r, bucket, key, query, payload)
+	default:
+		s.serveObject(w, r, bucket, key, payload)
+	}
+}
+
+func (s *Server) serveBucket(w http.ResponseWriter, r *http.Request, bucket string, query url.Values) {
+	objects, ok := s.buckets[bucket]
+	switch r.Method {
+	case http.MethodPut:
+		if ok {
+			writeError(w, http.StatusConflict, "BucketAlreadyOwnedByYou", "bucket already exists")
+			return
+		}
+		s.buckets[bucket] = map[string]*object{}
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	if !ok {
+		writeError(w, http.StatusNotFound, "NoSuchBucket", "bucket doesn't exist")
+		return
+	}
+	switch r.Method {
+	case http.MethodHead:
+		w.WriteHeader(http.StatusOK)
+	case http.MethodDelete:
+		if len(objects) > 0 {
+			writeError(w, http.StatusConflict, "BucketNotEmpty", "bucket isn't empty")
+			return
+		}
+		delete(s.buckets, bucket)
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodGet:
+		if query.Has("location") {
+			writeXML(w, struct {
+				XMLName xml.Name `xml:"LocationConstraint"`
+			}{})
+			return
+		}
+		s.list(w, bucket, objects, query.Get("prefix"))
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", r.Method)
+	}
+}
+
+type listContent struct {
+	Key          string
+	LastModified string
+	ETag         string
+	Size         int
+	StorageClass string
+}
+
+// list objects of bucket by prefix in one page
+func (s *Server) list(w http.ResponseWriter, bucket string, objects map[string]*object, prefix string) {
+	keys := make([]string, 0, len(objects))
+	for key := range objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	result := struct {
+		XMLName     xml.Name `xml:"ListBucketResult"`
+		Name        string
+		Prefix      string
+		KeyCount    int
+		MaxKeys     int
+		IsTruncated bool
+		Contents    []listContent
+	}{Name: bucket, Prefix: prefix, KeyCount: len(keys), MaxKeys: len(keys)}
+	for _, key := range keys {
+		result.Contents = append(result.Contents, listContent{
+			Key:          key,
+			LastModified: objects[key].modified.Format(time.RFC3339),
+			ETag:         objects[key].etag,
+			Size:         len(objects[key].data),
+			StorageClass: "STANDARD",
+		})
+	}
+	writeXML(w, result)
+}
+
+func (s *Server) serveObject(w http.ResponseWriter, r *http.Request, bucket, key string, payload *object) {
+	objects, ok := s.buckets[bucket]
+	if !ok {
+		writeError(w, http.StatusNotFound, "NoSuchBucket", "bucket doesn't exist")
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		objects[key] = payload
+		w.Header().Set("ETag", payload.etag)
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet, http.MethodHead:
+		obj, ok := objects[key]
+		if !ok {
+			writeError(w, http.StatusNotFound, "NoSuchKey", "object doesn't exist")
+			return
+		}
+		w.Header().Set("ETag", obj.etag)
+		w.Header().Set("Last-Modified", obj.modified.UTC().Format(http.TimeFormat))
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Length", strconv.Itoa(len(obj.data)))
+		w.WriteHeader(http.StatusOK)
+		if r.Method == http.MethodGet {
+			_, _ = w.Write(obj.data)
+		}
+	case http.MethodDelete:
+		delete(objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", r.Method)
+	}
+}
+
+type completePart struct {
+	PartNumber int
+	ETag       string
+}
+
+func (s *Server) serveUpload(
+	w http.ResponseWriter,
+	r *http.Request,
+	bucket, key string,
+	query url.Values,
+	payload *object,
+) {
+	if _, ok := s.buckets[bucket]; !ok {
+		writeError(w, http.StatusNotFound, "NoSuchBucket", "bucket doesn't exist")
+		return
+	}
+	if query.Has("uploads") && r.Method == http.MethodPost {
+		id := uuid.NewV4().String()
+		s.uploads[id] = &upload{bucket: bucket, key: key, parts: map[int]*object{}}
+		writeXML(w, struct {
+			XMLName  xml.Name `xml:"InitiateMultipartUploadResult"`
+			Bucket   string
+			Key      string
+			UploadId string
+		}{Bucket: bucket, Key: key, UploadId: id})
+		return
+	}
+	id := query.Get("uploadId")
+	up, ok := s.uploads[id]
+	if !ok || up.bucket != bucket || up.key != key {
+		writeError(w, http.StatusNotFound, "NoSuchUpload", "upload doesn't exist")
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		number, err := strconv.Atoi(query.Get("partNumber"))
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "InvalidArgument", "invalid part number")
+			return
+		}
+		up.parts[number] = payload
+		w.Header().Set("ETag", payload.etag)
+		w.WriteHeader(http.StatusOK)
+	case http.MethodPost:
+		var complete struct {
+			Parts []completePart `xml:"Part"`
+		}
+		if err := xml.NewDecoder(r.Body).Decode(&complete); err != nil {
+			writeError(w, http.StatusBadRequest, "MalformedXML", err.Error())
+			return
+		}
+		obj, err := up.complete(complete.Parts)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "InvalidPart", err.Error())
+			return
+		}
+		s.buckets[bucket][key] = obj
+		delete(s.uploads, id)
+		writeXML(w, struct {
+			XMLName  xml.Name `xml:"CompleteMultipartUploadResult"`
+			Location string
+			Bucket   string
+			Key      string
+			ETag     string
+		}{Location: "/" + bucket + "/" + key, Bucket: bucket, Key: key, ETag: obj.etag})
+	case http.MethodDelete:
+		delete(s.uploads, id)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, "MethodNotAllowed", r.Method)
+	}
+}
+
+// complete concatenate parts in order of completion request, etag is md5 of md5 of parts with count of parts
+func (u *upload) complete(parts []completePart) (*object, error) {
+	data := make([]byte, 0)
+	sums := make([]byte, 0, len(parts)*md5.Size)
+	for _, part := range parts {
+		uploaded, ok := u.parts[part.PartNumber]
+		if !ok || strings.Trim(uploaded.etag, `"`) != strings.Trim(part.ETag, `"`) {
+			return nil, fmt.Errorf("part %d wasn't uploaded", part.PartNumber)
+		}
+		data = append(data, uploaded.data...)
+		sum, _ := hex.DecodeString(strings.Trim(uploaded.etag, `"`))
+		sums = append(sums, sum...)
+	}
+	sum := md5.Sum(sums)
+	return &object{
+		data:     data,
+		etag:     fmt.Sprintf(`"%s-%d"`, hex.EncodeToString(sum[:]), len(parts)),
+		modified: time.Now(),
+	}, nil
+}
+
+// readObject read body of request, aws-chunked payload of streaming signature is decoded
+func readObject(r *http.Request) (*object, error) {
+	var body io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("X-Amz-Content-Sha256"), streamingPayload) {
+		body = newChunkedReader(r.Body)
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, err
+	}
+	sum := md5.Sum(data)
+	return &object{data: data, etag: `"` + hex.EncodeToString(sum[:]) + `"`, modified: time.Now()}, nil
+}
+
+// chunkedReader decode aws-chunked payload: hex size;chunk-signature=...\r\n data \r\n, last chunk has zero size
+type chunkedReader struct {
+	r    *bufio.Reader
+	left int
+	done bool
+}
+
+func newChunkedReader(r io.Reader) *chunkedReader {
+	return &chunkedReader{r: bufio.NewReader(r)}
+}
+
+func (c *chunkedReader) Read(p []byte) (int, error) {
+	if c.done {
+		return 0, io.EOF
+	}
+	if c.left == 0 {
+		line, err := c.r.ReadString('\n')
+		if err != nil {
+			return 0, err
+		}
+		size, _, _ := strings.Cut(strings.TrimSpace(line), ";")
+		left, err := strconv.ParseInt(size, 16, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid chunk size %q", size)
+		}
+		if left == 0 {
+			c.done = true
+			return 0, io.EOF
+		}
+		c.left = int(left)
+	}
+	if len(p) > c.left {
+		p = p[:c.left]
+	}
+	n, err := c.r.Read(p)
+	c.left -= n
+	if err != nil {
+		return n, err
+	}
+	if c.left == 0 {
+		if _, err = c.r.Discard(len("\r\n")); err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+func writeXML(w http.ResponseWriter, value interface{}) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write([]byte(xml.Header))
+	_ = xml.NewEncoder(w).Encode(value)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_, _ = w.Write([]byte(xml.Header))
+	_ = xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string
+		Message string
+	}{Code: code, Message: message})
+}
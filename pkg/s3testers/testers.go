@@ -0,0 +1,356 @@
+// Package s3testers bundled testers of S3 compatible object storage. Testers work with raw params of state,
+// so state must implement models.RawState and contain fields used by testers.
+package s3testers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"math/rand"
+	"time"
+
+	"github.com/minio/minio-go/v7"
+
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/clientfactory"
+	"github.com/lueurxax/e2e/pkg/models"
+)
+
+// method names of S3 testers
+const (
+	CreateBucket    = "S3CreateBucket"
+	DeleteBucket    = "S3DeleteBucket"
+	PutObject       = "S3PutObject"
+	GetObject       = "S3GetObject"
+	ListObjects     = "S3ListObjects"
+	DeleteObject    = "S3DeleteObject"
+	MultipartUpload = "S3MultipartUpload"
+)
+
+// fields of state used by S3 testers
+const (
+	BucketNameField     = "bucket_name"
+	ObjectKeyField      = "object_key"
+	ObjectSizeField     = "object_size"
+	ObjectChecksumField = "object_checksum" // hex sha256 of payload
+	ObjectPrefixField   = "object_prefix"   // optional prefix of listed objects
+	ObjectsCountField   = "objects_count"
+	PartSizeField       = "part_size" // optional size of part of multipart upload
+)
+
+var fieldTypes = map[string]string{
+	BucketNameField:     "string",
+	ObjectKeyField:      "string",
+	ObjectSizeField:     "int",
+	ObjectChecksumField: "string",
+	ObjectPrefixField:   "string",
+	ObjectsCountField:   "int",
+	PartSizeField:       "int",
+}
+
+// DefaultPartSize default size of part of multipart upload, minimal size of not last part in S3
+const DefaultPartSize = 5 << 20
+
+// listPage count of keys in one page of listing
+const listPage = 1000
+
+// run make requests by params of state and return values of returned fields
+type run func(ctx context.Context, client *clientfactory.S3Client, params map[string]interface{}) (map[string]interface{}, error)
+
+type tester struct {
+	name        string
+	description string
+	required    []string
+	returned    []string
+	run         run
+
+	state   models.RawState
+	clients *clientfactory.Set
+}
+
+func (t *tester) MethodName() (name string) {
+	return t.name
+}
+
+func (t *tester) RequiredFields() (fields []string) {
+	return t.required
+}
+
+func (t *tester) ReturnedFields() (fields []string) {
+	return t.returned
+}
+
+func (t *tester) Description() string {
+	return t.description
+}
+
+func (t *tester) ClientKinds() []string {
+	return []string{common.ClientS3}
+}
+
+// FieldTypes types of fields of state used by S3 testers
+func (t *tester) FieldTypes() map[string]string {
+	return fieldTypes
+}
+
+func (t *tester) Run(
+	ctx context.Context,
+	client string,
+	selector models.StateSelector,
+	opts *models.Options,
+) (models.StateSelector, error) {
+	s3, err := clientfactory.Get[*clientfactory.S3Client](t.clients, client)
+	if err != nil {
+		return nil, err
+	}
+	values, err := t.run(ctx, s3, t.state.Raw(selector))
+	if err != nil {
+		return nil, err
+	}
+	return t.state.NewRaw(selector, values)
+}
+
+func createBucket(ctx context.Context, client *clientfactory.S3Client, params map[string]interface{}) (map[string]interface{}, error) {
+	bucket, err := stringParam(params, BucketNameField)
+	if err != nil {
+		return nil, err
+	}
+	return nil, client.MakeBucket(ctx, bucket, minio.MakeBucketOptions{Region: client.Region})
+}
+
+func deleteBucket(ctx context.Context, client *clientfactory.S3Client, params map[string]interface{}) (map[string]interface{}, error) {
+	bucket, err := stringParam(params, BucketNameField)
+	if err != nil {
+		return nil, err
+	}
+	return nil, client.RemoveBucket(ctx, bucket)
+}
+
+// putObject upload random payload of object size and return its checksum
+func putObject(ctx context.Context, client *clientfactory.S3Client, params map[string]interface{}) (map[string]interface{}, error) {
+	bucket, key, err := objectParams(params)
+	if err != nil {
+		return nil, err
+	}
+	size, err := intParam(params, ObjectSizeField)
+	if err != nil {
+		return nil, err
+	}
+	payload, sum := newPayload(size)
+	if _, err = client.PutObject(ctx, bucket, key, payload, size, "", "", minio.PutObjectOptions{}); err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{ObjectChecksumField: hex.EncodeToString(sum.Sum(nil))}, nil
+}
+
+// getObject download object and verify its checksum
+func getObject(ctx context.Context, client *clientfactory.S3Client, params map[string]interface{}) (map[string]interface{}, error) {
+	bucket, key, err := objectParams(params)
+	if err != nil {
+		return nil, err
+	}
+	expected, err := stringParam(params, ObjectChecksumField)
+	if err != nil {
+		return nil, err
+	}
+	reader, _, _, err := client.GetObject(ctx, bucket, key, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	sum := sha256.New()
+	if _, err = io.Copy(sum, reader); err != nil {
+		return nil, err
+	}
+	if actual := hex.EncodeToString(sum.Sum(nil)); actual != expected {
+		return nil, common.ErrChecksumMismatch(bucket+"/"+key, expected, actual)
+	}
+	return nil, nil
+}
+
+// listObjects count objects of bucket with optional prefix
+func listObjects(ctx context.Context, client *clientfactory.S3Client, params map[string]interface{}) (map[string]interface{}, error) {
+	bucket, err := stringParam(params, BucketNameField)
+	if err != nil {
+		return nil, err
+	}
+	var prefix string
+	if _, ok := params[ObjectPrefixField]; ok {
+		if prefix, err = stringParam(params, ObjectPrefixField); err != nil {
+			return nil, err
+		}
+	}
+	count, token := 0, ""
+	for {
+		if err = ctx.Err(); err != nil {
+			return nil, err
+		}
+		result, err := client.ListObjectsV2(bucket, prefix, "", token, "", listPage)
+		if err != nil {
+			return nil, err
+		}
+		count += len(result.Contents)
+		if !result.IsTruncated {
+			break
+		}
+		token = result.NextContinuationToken
+	}
+	return map[string]interface{}{ObjectsCountField: count}, nil
+}
+
+func deleteObject(ctx context.Context, client *clientfactory.S3Client, params map[string]interface{}) (map[string]interface{}, error) {
+	bucket, key, err := objectParams(params)
+	if err != nil {
+		return nil, err
+	}
+	return nil, client.RemoveObject(ctx, bucket, key, minio.RemoveObjectOptions{})
+}
+
+// multipartUpload upload random payload of object size by parts and return its checksum,
+// failed upload is aborted
+func multipartUpload(ctx context.Context, client *clientfactory.S3Client, params map[string]interface{}) (map[string]interface{}, error) {
+	bucket, key, err := objectParams(params)
+	if err != nil {
+		return nil, err
+	}
+	size, err := intParam(params, ObjectSizeField)
+	if err != nil {
+		return nil, err
+	}
+	partSize := int64(DefaultPartSize)
+	if _, ok := params[PartSizeField]; ok {
+		if partSize, err = intParam(params, PartSizeField); err != nil {
+			return nil, err
+		}
+		if partSize <= 0 {
+			partSize = DefaultPartSize
+		}
+	}
+	id, err := client.NewMultipartUpload(ctx, bucket, key, minio.PutObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	payload, sum := newPayload(size)
+	parts := make([]minio.CompletePart, 0)
+	for uploaded, number := int64(0), 1; uploaded < size || number == 1; number++ {
+		length := partSize
+		if size-uploaded < length {
+			length = size - uploaded
+		}
+		var part minio.ObjectPart
+		part, err = client.PutObjectPart(ctx, bucket, key, id, number, io.LimitReader(payload, length), length,
+			minio.PutObjectPartOptions{})
+		if err != nil {
+			_ = client.AbortMultipartUpload(context.Background(), bucket, key, id)
+			return nil, err
+		}
+		parts = append(parts, minio.CompletePart{PartNumber: number, ETag: part.ETag})
+		uploaded += length
+	}
+	if _, err = client.CompleteMultipartUpload(ctx, bucket, key, id, parts, minio.PutObjectOptions{}); err != nil {
+		_ = client.AbortMultipartUpload(context.Background(), bucket, key, id)
+		return nil, err
+	}
+	return map[string]interface{}{ObjectChecksumField: hex.EncodeToString(sum.Sum(nil))}, nil
+}
+
+// newPayload return reader of random payload of size and hash, which is filled while payload is read
+func newPayload(size int64) (io.Reader, hash.Hash) {
+	sum := sha256.New()
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	return io.TeeReader(io.LimitReader(random, size), sum), sum
+}
+
+func objectParams(params map[string]interface{}) (bucket, key string, err error) {
+	if bucket, err = stringParam(params, BucketNameField); err != nil {
+		return
+	}
+	key, err = stringParam(params, ObjectKeyField)
+	return
+}
+
+func stringParam(params map[string]interface{}, key string) (value string, err error) {
+	raw, ok := params[key]
+	if !ok {
+		return "", common.ErrParameterRequired(key)
+	}
+	if err = common.AssignValue(&value, raw); err != nil {
+		return "", common.ErrParameterHasIncorrectType(key, "string")
+	}
+	return
+}
+
+func intParam(params map[string]interface{}, key string) (value int64, err error) {
+	raw, ok := params[key]
+	if !ok {
+		return 0, common.ErrParameterRequired(key)
+	}
+	if err = common.AssignValue(&value, raw); err != nil {
+		return 0, common.ErrParameterHasIncorrectType(key, "int")
+	}
+	return
+}
+
+// NewTesters construct S3 testers, state must implement models.RawState, testers use clients of s3 kind
+func NewTesters(state models.State, clients *clientfactory.Set) ([]models.Tester, error) {
+	raw, ok := state.(models.RawState)
+	if !ok {
+		return nil, common.ErrInvalidConfig("state doesn't implement raw state")
+	}
+	testers := []*tester{
+		{
+			name:        CreateBucket,
+			description: "create bucket",
+			required:    []string{BucketNameField},
+			run:         createBucket,
+		},
+		{
+			name:        DeleteBucket,
+			description: "delete bucket",
+			required:    []string{BucketNameField},
+			run:         deleteBucket,
+		},
+		{
+			name:        PutObject,
+			description: "put object of random payload of size and return its checksum",
+			required:    []string{BucketNameField, ObjectKeyField, ObjectSizeField},
+			returned:    []string{ObjectChecksumField},
+			run:         putObject,
+		},
+		{
+			name:        GetObject,
+			description: "get object and compare checksum of its payload",
+			required:    []string{BucketNameField, ObjectKeyField, ObjectChecksumField},
+			run:         getObject,
+		},
+		{
+			name:        ListObjects,
+			description: "list objects of bucket with optional prefix and return their count",
+			required:    []string{BucketNameField},
+			returned:    []string{ObjectsCountField},
+			run:         listObjects,
+		},
+		{
+			name:        DeleteObject,
+			description: "delete object",
+			required:    []string{BucketNameField, ObjectKeyField},
+			run:         deleteObject,
+		},
+		{
+			name:        MultipartUpload,
+			description: "upload object of random payload of size by parts and return its checksum",
+			required:    []string{BucketNameField, ObjectKeyField, ObjectSizeField},
+			returned:    []string{ObjectChecksumField},
+			run:         multipartUpload,
+		},
+	}
+	result := make([]models.Tester, len(testers))
+	for i, t := range testers {
+		t.state = raw
+		t.clients = clients
+		result[i] = t
+	}
+	return result, nil
+}
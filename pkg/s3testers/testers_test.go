@@ -0,0 +1,157 @@
+package s3testers
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/clientfactory"
+	"github.com/lueurxax/e2e/pkg/models"
+	"github.com/lueurxax/e2e/pkg/s3testers/s3fake"
+)
+
+const testClient = "storage"
+
+type selector int
+
+func (s selector) Index() int {
+	return int(s)
+}
+
+// rawState minimal raw state, every result of tester is a new state
+type rawState struct {
+	params []map[string]interface{}
+}
+
+func (s *rawState) Reset(int) []models.StateSelector {
+	return nil
+}
+
+func (s *rawState) Prepare(common.InitState) {}
+
+func (s *rawState) MergeToState([]models.StateSelector) {}
+
+func (s *rawState) MergeToStateRepeat(models.StateSelector) {}
+
+func (s *rawState) AddToState(selectors []models.StateSelector, _ ...map[string]interface{}) []models.StateSelector {
+	return selectors
+}
+
+func (s *rawState) Raw(selector models.StateSelector) map[string]interface{} {
+	return s.params[selector.Index()]
+}
+
+func (s *rawState) NewRaw(_ models.StateSelector, values map[string]interface{}) (models.StateSelector, error) {
+	return s.add(values), nil
+}
+
+func (s *rawState) add(params map[string]interface{}) models.StateSelector {
+	s.params = append(s.params, params)
+	return selector(len(s.params) - 1)
+}
+
+func newTesters(t *testing.T) (map[string]models.Tester, *rawState) {
+	t.Helper()
+	srv := httptest.NewServer(s3fake.New())
+	t.Cleanup(srv.Close)
+	clients, err := clientfactory.Build([]common.Client{{
+		Name:     testClient,
+		Type:     common.ClientS3,
+		Url:      srv.URL,
+		Email:    "access",
+		Password: "secret",
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = clients.Close() })
+	state := &rawState{}
+	testers, err := NewTesters(state, clients)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byName := make(map[string]models.Tester, len(testers))
+	for _, tester := range testers {
+		byName[tester.MethodName()] = tester
+	}
+	return byName, state
+}
+
+// runTester run tester with params and return values of its result
+func runTester(t *testing.T, testers map[string]models.Tester, state *rawState, name string,
+	params map[string]interface{}) (map[string]interface{}, error) {
+	t.Helper()
+	result, err := testers[name].Run(context.Background(), testClient, state.add(params), &models.Options{})
+	if err != nil {
+		return nil, err
+	}
+	return state.Raw(result), nil
+}
+
+func mustRun(t *testing.T, testers map[string]models.Tester, state *rawState, name string,
+	params map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	values, err := runTester(t, testers, state, name, params)
+	if err != nil {
+		t.Fatalf("%s: %s", name, err)
+	}
+	return values
+}
+
+func TestPutGetObjectChecksum(t *testing.T) {
+	testers, state := newTesters(t)
+	object := map[string]interface{}{BucketNameField: "bucket", ObjectKeyField: "dir/object", ObjectSizeField: 4096}
+	mustRun(t, testers, state, CreateBucket, object)
+
+	put := mustRun(t, testers, state, PutObject, object)
+	checksum, ok := put[ObjectChecksumField].(string)
+	if !ok || len(checksum) != 64 {
+		t.Fatalf("unexpected checksum %v", put[ObjectChecksumField])
+	}
+	mustRun(t, testers, state, GetObject, map[string]interface{}{
+		BucketNameField: "bucket", ObjectKeyField: "dir/object", ObjectChecksumField: checksum,
+	})
+
+	_, err := runTester(t, testers, state, GetObject, map[string]interface{}{
+		BucketNameField: "bucket", ObjectKeyField: "dir/object", ObjectChecksumField: "broken",
+	})
+	if err == nil {
+		t.Fatal("checksum mismatch isn't detected")
+	}
+
+	list := mustRun(t, testers, state, ListObjects, map[string]interface{}{
+		BucketNameField: "bucket", ObjectPrefixField: "dir/",
+	})
+	if list[ObjectsCountField] != 1 {
+		t.Fatalf("listed %v objects", list[ObjectsCountField])
+	}
+}
+
+func TestMultipartUpload(t *testing.T) {
+	testers, state := newTesters(t)
+	mustRun(t, testers, state, CreateBucket, map[string]interface{}{BucketNameField: "bucket"})
+
+	// the last part is smaller than others
+	object := map[string]interface{}{
+		BucketNameField: "bucket", ObjectKeyField: "large", ObjectSizeField: 2*DefaultPartSize + 1024,
+	}
+	upload := mustRun(t, testers, state, MultipartUpload, object)
+	object[ObjectChecksumField] = upload[ObjectChecksumField]
+	mustRun(t, testers, state, GetObject, object)
+
+	// empty object is uploaded by one part
+	empty := map[string]interface{}{
+		BucketNameField: "bucket", ObjectKeyField: "empty", ObjectSizeField: 0, PartSizeField: 1024,
+	}
+	upload = mustRun(t, testers, state, MultipartUpload, empty)
+	empty[ObjectChecksumField] = upload[ObjectChecksumField]
+	mustRun(t, testers, state, GetObject, empty)
+
+	if _, err := runTester(t, testers, state, DeleteBucket, object); err == nil {
+		t.Fatal("bucket with objects is deleted")
+	}
+	mustRun(t, testers, state, DeleteObject, object)
+	mustRun(t, testers, state, DeleteObject, empty)
+	mustRun(t, testers, state, DeleteBucket, object)
+}
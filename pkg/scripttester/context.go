@@ -0,0 +1,111 @@
+package scripttester
+
+import (
+	"fmt"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+
+	"github.com/lueurxax/e2e/pkg/models"
+)
+
+// scriptContext ctx argument of run: getters and setters of state and info of stage
+type scriptContext struct {
+	tester   string
+	raw      map[string]interface{}
+	returned map[string]struct{}
+	values   map[string]interface{}
+	client   string
+	opts     *models.Options
+}
+
+func newScriptContext(
+	tester string,
+	raw map[string]interface{},
+	returned map[string]struct{},
+	client string,
+	opts *models.Options,
+) *scriptContext {
+	return &scriptContext{
+		tester:   tester,
+		raw:      raw,
+		returned: returned,
+		values:   make(map[string]interface{}, len(returned)),
+		client:   client,
+		opts:     opts,
+	}
+}
+
+// value struct with get, has, set, client, launch_id and scenario
+func (c *scriptContext) value() starlark.Value {
+	var launchID, scenario string
+	if c.opts != nil {
+		launchID = c.opts.LaunchID
+		if c.opts.Conf != nil {
+			scenario = c.opts.Conf.Name
+		}
+	}
+	return starlarkstruct.FromStringDict(starlark.String("ctx"), starlark.StringDict{
+		"get":       starlark.NewBuiltin("get", c.get),
+		"has":       starlark.NewBuiltin("has", c.has),
+		"set":       starlark.NewBuiltin("set", c.setBuiltin),
+		"client":    starlark.String(c.client),
+		"launch_id": starlark.String(launchID),
+		"scenario":  starlark.String(scenario),
+	})
+}
+
+// get value of field, value set by script first, default if field isn't set
+func (c *scriptContext) get(
+	_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple,
+) (starlark.Value, error) {
+	var name string
+	var def starlark.Value = starlark.None
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "name", &name, "default?", &def); err != nil {
+		return nil, err
+	}
+	if value, ok := c.values[name]; ok {
+		return toStarlark(value)
+	}
+	value, ok := c.raw[name]
+	if !ok || value == nil {
+		return def, nil
+	}
+	return toStarlark(value)
+}
+
+func (c *scriptContext) has(
+	_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple,
+) (starlark.Value, error) {
+	var name string
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "name", &name); err != nil {
+		return nil, err
+	}
+	_, set := c.values[name]
+	value, ok := c.raw[name]
+	return starlark.Bool(set || ok && value != nil), nil
+}
+
+func (c *scriptContext) setBuiltin(
+	_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple,
+) (starlark.Value, error) {
+	var name string
+	var value starlark.Value
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "name", &name, "value", &value); err != nil {
+		return nil, err
+	}
+	return starlark.None, c.set(name, value)
+}
+
+// set returned field, fields which aren't returned by tester are errors
+func (c *scriptContext) set(name string, value starlark.Value) error {
+	if _, ok := c.returned[name]; !ok {
+		return fmt.Errorf("field %s isn't returned by tester %s", name, c.tester)
+	}
+	converted, err := fromStarlark(value)
+	if err != nil {
+		return fmt.Errorf("field %s: %w", name, err)
+	}
+	c.values[name] = converted
+	return nil
+}
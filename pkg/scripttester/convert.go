@@ -0,0 +1,117 @@
+package scripttester
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+
+	startime "go.starlark.net/lib/time"
+	"go.starlark.net/starlark"
+)
+
+// toStarlark convert raw value of state or decoded json to Starlark value
+func toStarlark(value interface{}) (starlark.Value, error) {
+	switch value := value.(type) {
+	case nil:
+		return starlark.None, nil
+	case starlark.Value:
+		return value, nil
+	case string:
+		return starlark.String(value), nil
+	case bool:
+		return starlark.Bool(value), nil
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return starlark.MakeInt64(i), nil
+		}
+		f, err := value.Float64()
+		return starlark.Float(f), err
+	case time.Time:
+		return startime.Time(value), nil
+	case time.Duration:
+		return startime.Duration(value), nil
+	case map[string]interface{}:
+		dict := starlark.NewDict(len(value))
+		for key, item := range value {
+			converted, err := toStarlark(item)
+			if err != nil {
+				return nil, err
+			}
+			if err = dict.SetKey(starlark.String(key), converted); err != nil {
+				return nil, err
+			}
+		}
+		return dict, nil
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return starlark.MakeInt64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return starlark.MakeUint64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return starlark.Float(v.Float()), nil
+	case reflect.Slice, reflect.Array:
+		items := make([]starlark.Value, v.Len())
+		for i := range items {
+			converted, err := toStarlark(v.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			items[i] = converted
+		}
+		return starlark.NewList(items), nil
+	}
+	return starlark.String(fmt.Sprint(value)), nil
+}
+
+// fromStarlark convert Starlark value to value for raw state or json
+func fromStarlark(value starlark.Value) (interface{}, error) {
+	switch value := value.(type) {
+	case starlark.NoneType:
+		return nil, nil
+	case starlark.Bool:
+		return bool(value), nil
+	case starlark.String:
+		return string(value), nil
+	case starlark.Bytes:
+		return string(value), nil
+	case starlark.Int:
+		if i, ok := value.Int64(); ok {
+			return i, nil
+		}
+		return nil, fmt.Errorf("int %s is too big", value)
+	case starlark.Float:
+		return float64(value), nil
+	case startime.Time:
+		return time.Time(value), nil
+	case startime.Duration:
+		return time.Duration(value), nil
+	case *starlark.Dict:
+		result := make(map[string]interface{}, value.Len())
+		for _, item := range value.Items() {
+			key, ok := starlark.AsString(item[0])
+			if !ok {
+				return nil, fmt.Errorf("key %s of dict isn't string", item[0])
+			}
+			converted, err := fromStarlark(item[1])
+			if err != nil {
+				return nil, err
+			}
+			result[key] = converted
+		}
+		return result, nil
+	case starlark.Indexable:
+		result := make([]interface{}, value.Len())
+		for i := range result {
+			converted, err := fromStarlark(value.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			result[i] = converted
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("unsupported value of type %s", value.Type())
+}
@@ -0,0 +1,317 @@
+package scripttester
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+
+	uuid "github.com/satori/go.uuid"
+	starjson "go.starlark.net/lib/json"
+	startime "go.starlark.net/lib/time"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/clientfactory"
+)
+
+// predeclared modules of scripts: http, json, time, crypto and assert
+func predeclared(clients *clientfactory.Set) starlark.StringDict {
+	modules := starlark.StringDict{
+		"http":   httpModule(clients),
+		"json":   starjson.Module,
+		"time":   startime.Module,
+		"crypto": cryptoModule,
+		"assert": assertModule,
+	}
+	modules.Freeze()
+	return modules
+}
+
+// httpModule requests with clients of http kind, client of stage is used by default,
+// absolute url may be requested without client
+func httpModule(clients *clientfactory.Set) *starlarkstruct.Module {
+	request := func(method string) func(
+		*starlark.Thread, *starlark.Builtin, starlark.Tuple, []starlark.Tuple,
+	) (starlark.Value, error) {
+		return func(
+			thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple,
+		) (starlark.Value, error) {
+			if method == "" {
+				if len(args) == 0 {
+					return nil, errors.New("missing argument for method")
+				}
+				name, ok := starlark.AsString(args[0])
+				if !ok {
+					return nil, errors.New("method must be string")
+				}
+				return doRequest(thread, fn, clients, strings.ToUpper(name), args[1:], kwargs)
+			}
+			return doRequest(thread, fn, clients, method, args, kwargs)
+		}
+	}
+	return &starlarkstruct.Module{
+		Name: "http",
+		Members: starlark.StringDict{
+			"request": starlark.NewBuiltin("http.request", request("")),
+			"get":     starlark.NewBuiltin("http.get", request(http.MethodGet)),
+			"post":    starlark.NewBuiltin("http.post", request(http.MethodPost)),
+			"put":     starlark.NewBuiltin("http.put", request(http.MethodPut)),
+			"patch":   starlark.NewBuiltin("http.patch", request(http.MethodPatch)),
+			"delete":  starlark.NewBuiltin("http.delete", request(http.MethodDelete)),
+		},
+	}
+}
+
+// doRequest make request with url, headers, body or json and client keyword arguments,
+// response is struct with status, headers, body and json()
+func doRequest(
+	thread *starlark.Thread,
+	fn *starlark.Builtin,
+	clients *clientfactory.Set,
+	method string,
+	args starlark.Tuple,
+	kwargs []starlark.Tuple,
+) (starlark.Value, error) {
+	client, _ := thread.Local(clientKey).(string)
+	var (
+		url      string
+		headers  *starlark.Dict
+		body     starlark.Value = starlark.None
+		jsonBody starlark.Value = starlark.None
+	)
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs,
+		"url", &url, "headers?", &headers, "body?", &body, "json?", &jsonBody, "client?", &client,
+	); err != nil {
+		return nil, err
+	}
+	httpClient := http.DefaultClient
+	err := common.ErrUnknownClient(client)
+	var configured *clientfactory.HTTPClient
+	if clients != nil {
+		configured, err = clientfactory.Get[*clientfactory.HTTPClient](clients, client)
+	}
+	switch {
+	case err == nil:
+		httpClient, url = configured.Client, configured.Resolve(url)
+	case !strings.Contains(url, "://"):
+		return nil, fmt.Errorf("client of relative url %s: %w", url, err)
+	}
+	var reader io.Reader
+	contentType := ""
+	switch {
+	case jsonBody != starlark.None:
+		value, err := fromStarlark(jsonBody)
+		if err != nil {
+			return nil, fmt.Errorf("json: %w", err)
+		}
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("json: %w", err)
+		}
+		reader, contentType = bytes.NewReader(data), "application/json"
+	case body != starlark.None:
+		data, ok := starlark.AsString(body)
+		if !ok {
+			return nil, errors.New("body must be string")
+		}
+		reader = strings.NewReader(data)
+	}
+	ctx, ok := thread.Local(contextKey).(context.Context)
+	if !ok {
+		return nil, errors.New("requests are allowed only in run")
+	}
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if headers != nil {
+		for _, item := range headers.Items() {
+			name, nameOk := starlark.AsString(item[0])
+			value, valueOk := starlark.AsString(item[1])
+			if !nameOk || !valueOk {
+				return nil, errors.New("headers must be strings")
+			}
+			req.Header.Set(name, value)
+		}
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	return newResponse(resp, data), nil
+}
+
+func newResponse(resp *http.Response, data []byte) starlark.Value {
+	headers := starlark.NewDict(len(resp.Header))
+	for name := range resp.Header {
+		_ = headers.SetKey(starlark.String(strings.ToLower(name)), starlark.String(resp.Header.Get(name)))
+	}
+	decode := func(
+		_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple,
+	) (starlark.Value, error) {
+		if err := starlark.UnpackArgs(fn.Name(), args, kwargs); err != nil {
+			return nil, err
+		}
+		var doc interface{}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.UseNumber()
+		if err := decoder.Decode(&doc); err != nil {
+			return nil, err
+		}
+		return toStarlark(doc)
+	}
+	return starlarkstruct.FromStringDict(starlark.String("response"), starlark.StringDict{
+		"status":  starlark.MakeInt(resp.StatusCode),
+		"headers": headers,
+		"body":    starlark.String(data),
+		"json":    starlark.NewBuiltin("response.json", decode),
+	})
+}
+
+// cryptoModule hashes in hex, hmac, base64 and uuid
+var cryptoModule = &starlarkstruct.Module{
+	Name: "crypto",
+	Members: starlark.StringDict{
+		"sha256": hashBuiltin("crypto.sha256", sha256.New),
+		"sha1":   hashBuiltin("crypto.sha1", sha1.New),
+		"md5":    hashBuiltin("crypto.md5", md5.New),
+		"hmac_sha256": starlark.NewBuiltin("crypto.hmac_sha256", func(
+			_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple,
+		) (starlark.Value, error) {
+			var key, data string
+			if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "key", &key, "data", &data); err != nil {
+				return nil, err
+			}
+			mac := hmac.New(sha256.New, []byte(key))
+			mac.Write([]byte(data))
+			return starlark.String(hex.EncodeToString(mac.Sum(nil))), nil
+		}),
+		"base64_encode": starlark.NewBuiltin("crypto.base64_encode", func(
+			_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple,
+		) (starlark.Value, error) {
+			var data string
+			if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "data", &data); err != nil {
+				return nil, err
+			}
+			return starlark.String(base64.StdEncoding.EncodeToString([]byte(data))), nil
+		}),
+		"base64_decode": starlark.NewBuiltin("crypto.base64_decode", func(
+			_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple,
+		) (starlark.Value, error) {
+			var data string
+			if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "data", &data); err != nil {
+				return nil, err
+			}
+			decoded, err := base64.StdEncoding.DecodeString(data)
+			if err != nil {
+				return nil, err
+			}
+			return starlark.String(decoded), nil
+		}),
+		"uuid": starlark.NewBuiltin("crypto.uuid", func(
+			_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple,
+		) (starlark.Value, error) {
+			if err := starlark.UnpackArgs(fn.Name(), args, kwargs); err != nil {
+				return nil, err
+			}
+			return starlark.String(uuid.NewV4().String()), nil
+		}),
+	},
+}
+
+func hashBuiltin(name string, newHash func() hash.Hash) *starlark.Builtin {
+	return starlark.NewBuiltin(name, func(
+		_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple,
+	) (starlark.Value, error) {
+		var data string
+		if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "data", &data); err != nil {
+			return nil, err
+		}
+		h := newHash()
+		h.Write([]byte(data))
+		return starlark.String(hex.EncodeToString(h.Sum(nil))), nil
+	})
+}
+
+// assertModule checks of script, failed check fails run of tester
+var assertModule = &starlarkstruct.Module{
+	Name: "assert",
+	Members: starlark.StringDict{
+		"eq": compareBuiltin("assert.eq", true),
+		"ne": compareBuiltin("assert.ne", false),
+		"true": starlark.NewBuiltin("assert.true", func(
+			_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple,
+		) (starlark.Value, error) {
+			var cond starlark.Value
+			var msg string
+			if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "cond", &cond, "msg?", &msg); err != nil {
+				return nil, err
+			}
+			if !cond.Truth() {
+				return nil, assertionFailed(fmt.Sprintf("%s is false", cond), msg)
+			}
+			return starlark.None, nil
+		}),
+		"fail": starlark.NewBuiltin("assert.fail", func(
+			_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple,
+		) (starlark.Value, error) {
+			var msg string
+			if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "msg", &msg); err != nil {
+				return nil, err
+			}
+			return nil, assertionFailed("failed", msg)
+		}),
+	},
+}
+
+func compareBuiltin(name string, equal bool) *starlark.Builtin {
+	return starlark.NewBuiltin(name, func(
+		_ *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple,
+	) (starlark.Value, error) {
+		var got, want starlark.Value
+		var msg string
+		if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "got", &got, "want", &want, "msg?", &msg); err != nil {
+			return nil, err
+		}
+		same, err := starlark.Equal(got, want)
+		if err != nil {
+			return nil, err
+		}
+		if same != equal {
+			op := "!="
+			if !equal {
+				op = "=="
+			}
+			return nil, assertionFailed(fmt.Sprintf("%s %s %s", got, op, want), msg)
+		}
+		return starlark.None, nil
+	})
+}
+
+func assertionFailed(reason, msg string) error {
+	if msg != "" {
+		reason += ": " + msg
+	}
+	return errors.New(reason)
+}
@@ -0,0 +1,160 @@
+// Package scripttester contains testers written in Starlark inside config or in referenced files
+package scripttester
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.starlark.net/starlark"
+
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/clientfactory"
+	"github.com/lueurxax/e2e/pkg/declarative"
+	"github.com/lueurxax/e2e/pkg/log"
+	"github.com/lueurxax/e2e/pkg/models"
+)
+
+// keys of thread locals of run
+const (
+	contextKey = "context"
+	clientKey  = "client"
+)
+
+// tester of Starlark script, globals of script are executed once and frozen, so runs are concurrent
+type tester struct {
+	conf     common.ScriptTester
+	state    models.RawState
+	clients  *clientfactory.Set
+	run      starlark.Callable
+	returned map[string]struct{}
+	logger   log.Logger
+}
+
+func (t *tester) MethodName() (name string) {
+	return t.conf.Name
+}
+
+func (t *tester) RequiredFields() (fields []string) {
+	return t.conf.Required
+}
+
+func (t *tester) ReturnedFields() (fields []string) {
+	return t.conf.Returned
+}
+
+func (t *tester) Description() string {
+	return t.conf.GetDescription()
+}
+
+func (t *tester) ClientKinds() []string {
+	return nil
+}
+
+// Run call run(ctx) of script with selected state, returned fields are set by ctx.set or returned in dict
+func (t *tester) Run(
+	ctx context.Context,
+	client string,
+	selector models.StateSelector,
+	opts *models.Options,
+) (models.StateSelector, error) {
+	ctx, cancel := context.WithTimeout(ctx, t.conf.GetTimeout())
+	defer cancel()
+	thread := &starlark.Thread{Name: t.conf.Name, Print: t.print}
+	thread.SetLocal(contextKey, ctx)
+	thread.SetLocal(clientKey, client)
+	stop := context.AfterFunc(ctx, func() {
+		thread.Cancel(ctx.Err().Error())
+	})
+	defer stop()
+
+	scriptCtx := newScriptContext(t.conf.Name, t.state.Raw(selector), t.returned, client, opts)
+	result, err := starlark.Call(thread, t.run, starlark.Tuple{scriptCtx.value()}, nil)
+	if err != nil {
+		if evalErr, ok := err.(*starlark.EvalError); ok {
+			return nil, common.ErrScriptFailed(t.conf.Name, evalErr.Backtrace())
+		}
+		return nil, common.ErrScriptFailed(t.conf.Name, err.Error())
+	}
+	switch result := result.(type) {
+	case starlark.NoneType:
+	case *starlark.Dict:
+		for _, item := range result.Items() {
+			key, ok := starlark.AsString(item[0])
+			if !ok {
+				return nil, common.ErrScriptFailed(t.conf.Name, fmt.Sprintf("returned key %s isn't string", item[0]))
+			}
+			if err = scriptCtx.set(key, item[1]); err != nil {
+				return nil, common.ErrScriptFailed(t.conf.Name, err.Error())
+			}
+		}
+	default:
+		return nil, common.ErrScriptFailed(t.conf.Name, fmt.Sprintf("run returned %s, want dict or None", result.Type()))
+	}
+	return t.state.NewRaw(selector, scriptCtx.values)
+}
+
+// print log output of print of script, secrets are redacted by logger
+func (t *tester) print(_ *starlark.Thread, msg string) {
+	t.logger.Info(msg)
+}
+
+// New construct script tester, state must implement models.RawState, http requests use clients of http kind.
+// Script is executed once, it must define function run(ctx). Output of print is logged by logger.
+func New(
+	conf common.ScriptTester, state models.State, clients *clientfactory.Set, logger log.Logger,
+) (models.Tester, error) {
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+	raw, ok := state.(models.RawState)
+	if !ok {
+		return nil, common.ErrInvalidConfig("state doesn't implement raw state")
+	}
+	if err := declarative.CheckFields(conf.Name, state, conf.Required, conf.Returned); err != nil {
+		return nil, err
+	}
+	filename, src := conf.Name+".star", []byte(conf.Script)
+	if conf.File != "" {
+		data, err := os.ReadFile(conf.File)
+		if err != nil {
+			return nil, common.ErrInvalidConfig(fmt.Sprintf("script tester %s: %s", conf.Name, err))
+		}
+		filename, src = conf.File, data
+	}
+	t := &tester{
+		conf:     conf,
+		state:    raw,
+		clients:  clients,
+		returned: make(map[string]struct{}, len(conf.Returned)),
+		logger:   logger.WithField("tester", conf.Name),
+	}
+	for _, field := range conf.Returned {
+		t.returned[field] = struct{}{}
+	}
+	thread := &starlark.Thread{Name: conf.Name, Print: t.print}
+	globals, err := starlark.ExecFile(thread, filename, src, predeclared(clients))
+	if err != nil {
+		return nil, common.ErrInvalidConfig(fmt.Sprintf("script tester %s: %s", conf.Name, err))
+	}
+	globals.Freeze()
+	if t.run, ok = globals["run"].(starlark.Callable); !ok {
+		return nil, common.ErrInvalidConfig(fmt.Sprintf("script tester %s doesn't define function run(ctx)", conf.Name))
+	}
+	return t, nil
+}
+
+// NewTesters construct script testers of configs
+func NewTesters(
+	confs []common.ScriptTester, state models.State, clients *clientfactory.Set, logger log.Logger,
+) ([]models.Tester, error) {
+	testers := make([]models.Tester, len(confs))
+	for i, conf := range confs {
+		tester, err := New(conf, state, clients, logger)
+		if err != nil {
+			return nil, err
+		}
+		testers[i] = tester
+	}
+	return testers, nil
+}
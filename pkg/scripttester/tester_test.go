@@ -0,0 +1,82 @@
+package scripttester
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/log"
+	"github.com/lueurxax/e2e/pkg/models"
+)
+
+type selector int
+
+func (s selector) Index() int {
+	return int(s)
+}
+
+// rawState state of raw params with types of fields, results of runs are appended
+type rawState struct {
+	models.State
+	params []map[string]interface{}
+}
+
+func (s *rawState) add(params map[string]interface{}) models.StateSelector {
+	s.params = append(s.params, params)
+	return selector(len(s.params) - 1)
+}
+
+func (s *rawState) Raw(sel models.StateSelector) map[string]interface{} {
+	return s.params[sel.Index()]
+}
+
+func (s *rawState) NewRaw(_ models.StateSelector, values map[string]interface{}) (models.StateSelector, error) {
+	return s.add(values), nil
+}
+
+func (s *rawState) FieldTypes() map[string]string {
+	return map[string]string{"name": "string", "greeting": "string"}
+}
+
+func TestScriptTester(t *testing.T) {
+	var logs bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&logs)
+	state := &rawState{}
+	conf := common.ScriptTester{
+		Name: "Greet",
+		Script: `
+def run(ctx):
+    print("greet " + ctx.get("name"))
+    return {"greeting": "hello " + ctx.get("name")}
+`,
+		Required: []string{"name"},
+		Returned: []string{"greeting"},
+	}
+	tester, err := New(conf, state, nil, log.NewLogger(logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := tester.Run(context.Background(), "", state.add(map[string]interface{}{"name": "bob"}), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if greeting := state.Raw(result)["greeting"]; greeting != "hello bob" {
+		t.Fatalf("unexpected greeting %v", greeting)
+	}
+	// output of print is logged with name of tester
+	if output := logs.String(); !strings.Contains(output, `msg="greet bob"`) ||
+		!strings.Contains(output, "tester=Greet") {
+		t.Fatalf("output of print isn't logged: %s", output)
+	}
+
+	conf.Returned = []string{"greting"}
+	if _, err = New(conf, state, nil, log.NewLogger(logger)); err == nil ||
+		!strings.Contains(err.Error(), "tester Greet uses unknown fields of state: greting") {
+		t.Fatalf("expected error of unknown fields, got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// vaultTimeout timeout of request of vault
+const vaultTimeout = 30 * time.Second
+
+// resolveEnv value of environment variable, variable must be set
+func resolveEnv(_ context.Context, name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", errors.New("environment variable isn't set")
+	}
+	return value, nil
+}
+
+// resolveFile content of file without trailing newline
+func resolveFile(_ context.Context, path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(data), "\r\n"), nil
+}
+
+// vault provider read secrets of kv engine by http api of vault, address and token are taken
+// from VAULT_ADDR and VAULT_TOKEN. Reference is path#key, like secret/data/e2e#password for kv v2,
+// secrets of path are requested once.
+type vault struct {
+	http *http.Client
+
+	mu    sync.Mutex
+	paths map[string]map[string]interface{}
+}
+
+func (v *vault) Resolve(ctx context.Context, ref string) (string, error) {
+	path, key, ok := strings.Cut(ref, "#")
+	if !ok || path == "" || key == "" {
+		return "", errors.New("reference of vault must be path#key")
+	}
+	data, err := v.read(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	value, ok := data[key]
+	if !ok {
+		return "", fmt.Errorf("key %s not found", key)
+	}
+	return fmt.Sprint(value), nil
+}
+
+func (v *vault) read(ctx context.Context, path string) (map[string]interface{}, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if data, ok := v.paths[path]; ok {
+		return data, nil
+	}
+	addr := os.Getenv("VAULT_ADDR")
+	if addr == "" {
+		return nil, errors.New("VAULT_ADDR isn't set")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet,
+		strings.TrimSuffix(addr, "/")+"/v1/"+strings.TrimPrefix(path, "/"), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", os.Getenv("VAULT_TOKEN"))
+	resp, err := v.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("vault responded with status %d", resp.StatusCode)
+	}
+	var secret struct {
+		Data map[string]interface{} `json:"data"`
+	}
+	if err = json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return nil, err
+	}
+	data := secret.Data
+	// kv v2 nests secrets with their metadata
+	if nested, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok = data["metadata"]; ok {
+			data = nested
+		}
+	}
+	v.paths[path] = data
+	return data, nil
+}
+
+func newVault() *vault {
+	return &vault{http: &http.Client{Timeout: vaultTimeout}, paths: map[string]map[string]interface{}{}}
+}
@@ -0,0 +1,79 @@
+package secrets
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Redact replace remembered secrets in text
+func Redact(text string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, secret := range known {
+		if strings.Contains(text, secret) {
+			text = strings.ReplaceAll(text, secret, Mask)
+		}
+	}
+	return text
+}
+
+// RedactJSON replace remembered secrets in json document, secrets are searched also in escaped form
+func RedactJSON(data []byte) []byte {
+	mu.RLock()
+	defer mu.RUnlock()
+	text := string(data)
+	for _, secret := range known {
+		escaped, _ := json.Marshal(secret)
+		for _, form := range []string{secret, string(escaped[1 : len(escaped)-1])} {
+			if strings.Contains(text, form) {
+				text = strings.ReplaceAll(text, form, Mask)
+			}
+		}
+	}
+	return []byte(text)
+}
+
+// RedactError return error with redacted message, error without secrets is returned as is
+func RedactError(err error) error {
+	if err == nil {
+		return nil
+	}
+	message := err.Error()
+	if redacted := Redact(message); redacted != message {
+		return errors.New(redacted)
+	}
+	return err
+}
+
+// RedactValue redact strings, errors and dumped state of maps and slices, other values are returned as is
+func RedactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case string:
+		return Redact(v)
+	case error:
+		return RedactError(v)
+	case fmt.Stringer:
+		return Redact(v.String())
+	case map[string]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			result[key] = RedactValue(item)
+		}
+		return result
+	case []map[string]interface{}:
+		result := make([]map[string]interface{}, len(v))
+		for i, item := range v {
+			result[i] = RedactValue(item).(map[string]interface{})
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for i, item := range v {
+			result[i] = RedactValue(item)
+		}
+		return result
+	}
+	return value
+}
@@ -0,0 +1,131 @@
+// Package secrets resolve secret references in config: ${env:NAME}, ${file:/path} and ${vault:path#key}.
+// Providers of other schemes are registered with Register. Resolved values are remembered
+// and redacted in logs and responses, $${ is escaped ${.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/lueurxax/e2e/common"
+)
+
+// Mask replacement of redacted secrets
+const Mask = "******"
+
+// minRedacted minimal length of redacted secret, shorter values would mask unrelated text
+const minRedacted = 4
+
+// reference ${scheme:ref}, $${ escapes reference
+var reference = regexp.MustCompile(`\$?\$\{([a-zA-Z][a-zA-Z0-9_-]*):([^}]*)\}`)
+
+// Provider resolve reference of its scheme to value of secret
+type Provider interface {
+	Resolve(ctx context.Context, ref string) (value string, err error)
+}
+
+// ProviderFunc adapter of function to Provider
+type ProviderFunc func(ctx context.Context, ref string) (string, error)
+
+// Resolve call function
+func (f ProviderFunc) Resolve(ctx context.Context, ref string) (string, error) {
+	return f(ctx, ref)
+}
+
+var (
+	mu        sync.RWMutex
+	providers = map[string]Provider{
+		"env":   ProviderFunc(resolveEnv),
+		"file":  ProviderFunc(resolveFile),
+		"vault": newVault(),
+	}
+	// known resolved values sorted by length, so longer secrets are redacted before their parts
+	known []string
+)
+
+// Register provider of scheme, provider of already registered scheme is replaced
+func Register(scheme string, provider Provider) {
+	mu.Lock()
+	defer mu.Unlock()
+	providers[scheme] = provider
+}
+
+func provider(scheme string) (Provider, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	p, ok := providers[scheme]
+	return p, ok
+}
+
+// Remember value as secret, so it is redacted, values of references are remembered on resolving
+func Remember(value string) {
+	if len(value) < minRedacted {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	i := sort.Search(len(known), func(i int) bool {
+		return len(known[i]) < len(value) || len(known[i]) == len(value) && known[i] >= value
+	})
+	if i < len(known) && known[i] == value {
+		return
+	}
+	known = append(known, "")
+	copy(known[i+1:], known[i:])
+	known[i] = value
+}
+
+// Resolve references in value
+func Resolve(ctx context.Context, value string) (string, error) {
+	var resolveErr error
+	result := reference.ReplaceAllStringFunc(value, func(ref string) string {
+		if strings.HasPrefix(ref, "$$") {
+			return ref[1:]
+		}
+		if resolveErr != nil {
+			return ref
+		}
+		match := reference.FindStringSubmatch(ref)
+		p, ok := provider(match[1])
+		if !ok {
+			resolveErr = common.ErrSecretNotResolved(ref, "unknown provider "+match[1])
+			return ref
+		}
+		secret, err := p.Resolve(ctx, match[2])
+		if err != nil {
+			resolveErr = common.ErrSecretNotResolved(ref, err.Error())
+			return ref
+		}
+		Remember(secret)
+		return secret
+	})
+	return result, resolveErr
+}
+
+// ResolveNode resolve references in string scalars of yaml document. Plain scalar, which is only reference,
+// gets tag of resolved value, so ${env:PORT} is decoded as integer.
+func ResolveNode(ctx context.Context, node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode && strings.Contains(node.Value, "${") {
+		whole := reference.FindString(node.Value) == node.Value && !strings.HasPrefix(node.Value, "$$")
+		value, err := Resolve(ctx, node.Value)
+		if err != nil {
+			return common.ErrInvalidConfig(fmt.Sprintf("line %d: %s", node.Line, err))
+		}
+		node.Value = value
+		if whole && node.Style == 0 {
+			node.Tag = ""
+		}
+	}
+	for _, child := range node.Content {
+		if err := ResolveNode(ctx, child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -17,7 +17,7 @@ var (
 	configPath = flag.String("config", "./.stresscfg.yaml", "path to config file")
 )
 
-//go:embed selector.gotpl stated_method_wrapper.gotpl state.gotpl stress_storage.gotpl
+//go:embed selector.gotpl stated_method_wrapper.gotpl state.gotpl stress_storage.gotpl stream.gotpl
 var f embed.FS
 
 func main() {
@@ -38,7 +38,8 @@ func main() {
 		"selector.gotpl",
 		"stated_method_wrapper.gotpl",
 		"state.gotpl",
-		"stress_storage.gotpl")
+		"stress_storage.gotpl",
+		"stream.gotpl")
 	if err != nil {
 		panic(err)
 	}
@@ -58,6 +59,10 @@ func main() {
 	if err := execute(tmpl, cfg.Parameters.PathToGenerated, "stress_storage", cfg.Parameters); err != nil {
 		panic(err)
 	}
+
+	if err := execute(tmpl, cfg.Parameters.PathToGenerated, "stream", cfg.Parameters); err != nil {
+		panic(err)
+	}
 }
 
 func execute(tmpl *template.Template, path, name string, params models.Params) error {
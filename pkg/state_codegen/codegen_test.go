@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"text/template"
+
+	"github.com/lueurxax/e2e/pkg/state_codegen/models"
+)
+
+// TestGeneratedState generate state into testdata and run tests of generated code with go tool
+func TestGeneratedState(t *testing.T) {
+	if testing.Short() {
+		t.Skip("generated state is built by go tool")
+	}
+	goTool, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go tool isn't found")
+	}
+	dir, err := os.MkdirTemp("testdata", "scenariostate")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.RemoveAll(dir) })
+
+	params := models.Params{
+		Fields: []models.Field{
+			{SnakeName: "login", Type: "string"},
+			{SnakeName: "time_interval", Type: "time.Duration"},
+			{SnakeName: "tickers", Type: "[]string"},
+			{SnakeName: "time", Type: "time.Time"},
+		},
+		ClientName:      "*Client",
+		ClientPath:      "github.com/lueurxax/e2e/pkg/state_codegen/testdata/client",
+		PathToGenerated: dir + string(filepath.Separator),
+	}
+	tmpl, err := template.New("").ParseFS(f, "*.gotpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"selector", "stated_method_wrapper", "state", "stress_storage", "stream"} {
+		if err = execute(tmpl, params.PathToGenerated, name, params); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+	tests, err := os.ReadFile(filepath.Join("testdata", "state_test.go.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.WriteFile(filepath.Join(dir, "state_test.go"), tests, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(goTool, "test", ".")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("tests of generated state failed: %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,5 @@
+// Package client client of generated state of codegen tests
+package client
+
+// Client client of tested methods
+type Client struct{}
@@ -0,0 +1,384 @@
+package testerplugin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/clientfactory"
+	"github.com/lueurxax/e2e/pkg/log"
+	"github.com/lueurxax/e2e/pkg/models"
+)
+
+const (
+	// DescribeTimeout timeout of describe of plugin on start
+	DescribeTimeout = 10 * time.Second
+	// StopTimeout time for plugin to exit after stdin is closed, then it is killed
+	StopTimeout = 5 * time.Second
+)
+
+// Plugins running processes of plugins and their testers
+type Plugins struct {
+	plugins []*plugin
+	testers []models.Tester
+}
+
+// Testers testers of all plugins
+func (p *Plugins) Testers() []models.Tester {
+	return p.testers
+}
+
+// Close stop processes of plugins
+func (p *Plugins) Close() (err error) {
+	for _, plug := range p.plugins {
+		if stopErr := plug.stop(); stopErr != nil && err == nil {
+			err = stopErr
+		}
+	}
+	return
+}
+
+// LoadDir start executable files of directory as plugins in order of names, hidden files are skipped.
+// State must implement models.RawState, clients of stages are taken from clients.
+func LoadDir(dir string, state models.State, clients *clientfactory.Set, logger log.Logger) (*Plugins, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, err
+		}
+		if info.Mode()&0o111 != 0 {
+			paths = append(paths, filepath.Join(dir, entry.Name()))
+		}
+	}
+	sort.Strings(paths)
+	return Load(paths, state, clients, logger)
+}
+
+// Load start plugins of executables, all started plugins are stopped on error.
+// Lines of stderr of plugins and invalid messages of plugins are logged by logger.
+func Load(paths []string, state models.State, clients *clientfactory.Set, logger log.Logger) (*Plugins, error) {
+	raw, ok := state.(models.RawState)
+	if !ok {
+		return nil, common.ErrInvalidConfig("state doesn't implement raw state")
+	}
+	plugins := &Plugins{}
+	for _, path := range paths {
+		plug, err := start(path, logger.WithField("plugin", path))
+		if err != nil {
+			_ = plugins.Close()
+			return nil, err
+		}
+		plugins.plugins = append(plugins.plugins, plug)
+		ctx, cancel := context.WithTimeout(context.Background(), DescribeTimeout)
+		var result DescribeResult
+		err = plug.call(ctx, MethodDescribe, nil, &result)
+		cancel()
+		if err != nil {
+			_ = plugins.Close()
+			return nil, err
+		}
+		for _, info := range result.Testers {
+			plugins.testers = append(plugins.testers, &tester{info: info, plugin: plug, state: raw, clients: clients})
+		}
+	}
+	return plugins, nil
+}
+
+type plugin struct {
+	name   string
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	logger log.Logger
+	// logged closed after stderr of plugin is read
+	logged chan struct{}
+
+	// writeMu guards stdin, messages are written concurrently
+	writeMu sync.Mutex
+	// mu guards pending calls and reason of exit
+	mu      sync.Mutex
+	pending map[uint64]chan *Response
+	nextID  uint64
+	exited  chan struct{}
+	reason  string
+}
+
+// start process of plugin, lines of its stderr are logged
+func start(path string, logger log.Logger) (*plugin, error) {
+	cmd := exec.Command(path)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err = cmd.Start(); err != nil {
+		return nil, common.ErrPlugin(path, err.Error())
+	}
+	p := &plugin{
+		name:    path,
+		cmd:     cmd,
+		stdin:   stdin,
+		logger:  logger,
+		logged:  make(chan struct{}),
+		pending: map[uint64]chan *Response{},
+		exited:  make(chan struct{}),
+	}
+	go p.log(stderr)
+	go p.read(stdout)
+	return p, nil
+}
+
+// read responses until plugin exits, then pending calls fail
+func (p *plugin) read(stdout io.Reader) {
+	reader := bufio.NewReader(stdout)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			p.dispatch(line)
+		}
+		if err != nil {
+			break
+		}
+	}
+	// pipes are closed by wait, so stderr is read first
+	<-p.logged
+	reason := "exited"
+	if err := p.cmd.Wait(); err != nil {
+		reason = "exited: " + err.Error()
+	}
+	p.mu.Lock()
+	p.reason = reason
+	p.mu.Unlock()
+	close(p.exited)
+}
+
+// log lines of stderr of plugin until it is closed
+func (p *plugin) log(stderr io.Reader) {
+	defer close(p.logged)
+	lines := bufio.NewScanner(stderr)
+	for lines.Scan() {
+		if line := strings.TrimSpace(lines.Text()); line != "" {
+			p.logger.Debug(line)
+		}
+	}
+	if err := lines.Err(); err != nil {
+		p.logger.WithError(err).Warn("read stderr")
+		// rest of stderr is dropped, so plugin isn't blocked on write
+		_, _ = io.Copy(io.Discard, stderr)
+	}
+}
+
+func (p *plugin) dispatch(line []byte) {
+	resp := &Response{}
+	if err := json.Unmarshal(line, resp); err != nil {
+		p.logger.WithError(err).Warn("invalid message")
+		return
+	}
+	p.mu.Lock()
+	ch, ok := p.pending[resp.ID]
+	delete(p.pending, resp.ID)
+	p.mu.Unlock()
+	if ok {
+		ch <- resp
+	}
+}
+
+// call method of plugin, cancelled call is cancelled in plugin too
+func (p *plugin) call(ctx context.Context, method string, params, result interface{}) error {
+	select {
+	case <-p.exited:
+		return common.ErrPlugin(p.name, p.reason)
+	default:
+	}
+	id := atomic.AddUint64(&p.nextID, 1)
+	ch := make(chan *Response, 1)
+	p.mu.Lock()
+	p.pending[id] = ch
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		delete(p.pending, id)
+		p.mu.Unlock()
+	}()
+	if err := p.send(id, method, params); err != nil {
+		return err
+	}
+	select {
+	case resp := <-ch:
+		if resp.Error != nil {
+			return common.ErrPlugin(p.name, resp.Error.Message)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(resp.Result))
+		decoder.UseNumber()
+		if err := decoder.Decode(result); err != nil {
+			return common.ErrPlugin(p.name, fmt.Sprintf("invalid result of %s: %s", method, err))
+		}
+		return nil
+	case <-ctx.Done():
+		_ = p.send(0, MethodCancel, CancelParams{ID: id})
+		return ctx.Err()
+	case <-p.exited:
+		return common.ErrPlugin(p.name, p.reason)
+	}
+}
+
+// send request, notification if id is zero
+func (p *plugin) send(id uint64, method string, params interface{}) error {
+	req := Request{JSONRPC: version, ID: id, Method: method}
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return err
+		}
+		req.Params = data
+	}
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+	p.writeMu.Lock()
+	defer p.writeMu.Unlock()
+	if _, err = p.stdin.Write(append(data, '\n')); err != nil {
+		return common.ErrPlugin(p.name, err.Error())
+	}
+	return nil
+}
+
+// stop close stdin of plugin and kill it, if it doesn't exit in time
+func (p *plugin) stop() error {
+	_ = p.stdin.Close()
+	select {
+	case <-p.exited:
+		return nil
+	case <-time.After(StopTimeout):
+	}
+	if err := p.cmd.Process.Kill(); err != nil {
+		return common.ErrPlugin(p.name, err.Error())
+	}
+	<-p.exited
+	return nil
+}
+
+// tester of plugin, it sends raw params of selected state to plugin and stores returned fields
+type tester struct {
+	info    TesterInfo
+	plugin  *plugin
+	state   models.RawState
+	clients *clientfactory.Set
+}
+
+func (t *tester) MethodName() string {
+	return t.info.Name
+}
+
+func (t *tester) RequiredFields() []string {
+	return t.info.Required
+}
+
+func (t *tester) ReturnedFields() []string {
+	return t.info.Returned
+}
+
+func (t *tester) Description() string {
+	return t.info.Description
+}
+
+func (t *tester) ClientKinds() []string {
+	return t.info.Clients
+}
+
+func (t *tester) FieldTypes() map[string]string {
+	return t.info.Types
+}
+
+// Run tester in process of plugin
+func (t *tester) Run(
+	ctx context.Context,
+	client string,
+	selector models.StateSelector,
+	opts *models.Options,
+) (models.StateSelector, error) {
+	params := RunParams{Tester: t.info.Name, State: t.state.Raw(selector)}
+	if opts != nil {
+		params.LaunchID = opts.LaunchID
+		if opts.Conf != nil {
+			params.Scenario = opts.Conf.Name
+		}
+	}
+	if client != "" {
+		info, err := t.clientInfo(ctx, client)
+		if err != nil {
+			return nil, err
+		}
+		params.Client = info
+	}
+	var result RunResult
+	if err := t.plugin.call(ctx, MethodRun, params, &result); err != nil {
+		return nil, err
+	}
+	return t.state.NewRaw(selector, result.Fields)
+}
+
+// clientInfo config of client with token and credentials of its auth in headers
+func (t *tester) clientInfo(ctx context.Context, name string) (*ClientInfo, error) {
+	if t.clients == nil {
+		return nil, common.ErrUnknownClient(name)
+	}
+	for _, conf := range t.clients.Configs() {
+		if conf.Name != name {
+			continue
+		}
+		info := &ClientInfo{
+			Name:    conf.Name,
+			Type:    conf.GetType(),
+			URL:     conf.Url,
+			Headers: make(map[string]string, len(conf.Headers)+1),
+			Extra:   conf.Extra,
+		}
+		for header, value := range conf.Headers {
+			info.Headers[header] = value
+		}
+		if conf.Token != "" {
+			info.Headers["Authorization"] = "Bearer " + conf.Token
+		}
+		if conf.Auth != nil {
+			auth, err := t.clients.Auth(name)
+			if err != nil {
+				return nil, err
+			}
+			header, value, err := auth.Credentials(ctx)
+			if err != nil {
+				return nil, err
+			}
+			info.Headers[header] = value
+		}
+		return info, nil
+	}
+	return nil, common.ErrUnknownClient(name)
+}
@@ -0,0 +1,286 @@
+package testerplugin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/clientfactory"
+	"github.com/lueurxax/e2e/pkg/log"
+	"github.com/lueurxax/e2e/pkg/models"
+)
+
+// pluginEnv environment variable, which turns test binary into plugin serving testers of test
+const pluginEnv = "E2E_TESTER_PLUGIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(pluginEnv) != "" {
+		fmt.Fprintln(os.Stderr, "plugin started")
+		if err := Serve(&echoTester{}, &blockTester{}, &activeTester{}, &crashTester{}); err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// blocked count of running block testers in process of plugin
+var blocked int64
+
+// echoTester greet name of state and return client of stage
+type echoTester struct{}
+
+func (t *echoTester) Info() TesterInfo {
+	return TesterInfo{
+		Name:        "Echo",
+		Description: "greet user",
+		Required:    []string{"name"},
+		Returned:    []string{"greeting", "url", "auth", "launch"},
+		Clients:     []string{common.ClientHTTP},
+		Types:       map[string]string{"name": "string"},
+	}
+}
+
+func (t *echoTester) Run(_ context.Context, params *RunParams) (map[string]interface{}, error) {
+	fields := map[string]interface{}{"greeting": fmt.Sprintf("hello %s", params.State["name"]), "launch": params.LaunchID}
+	if params.Client != nil {
+		fields["url"] = params.Client.URL
+		fields["auth"] = params.Client.Headers["Authorization"]
+	}
+	return fields, nil
+}
+
+// blockTester run until it is cancelled
+type blockTester struct{}
+
+func (t *blockTester) Info() TesterInfo {
+	return TesterInfo{Name: "Block"}
+}
+
+func (t *blockTester) Run(ctx context.Context, _ *RunParams) (map[string]interface{}, error) {
+	atomic.AddInt64(&blocked, 1)
+	defer atomic.AddInt64(&blocked, -1)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+// activeTester return count of running block testers
+type activeTester struct{}
+
+func (t *activeTester) Info() TesterInfo {
+	return TesterInfo{Name: "Active", Returned: []string{"blocked"}}
+}
+
+func (t *activeTester) Run(context.Context, *RunParams) (map[string]interface{}, error) {
+	return map[string]interface{}{"blocked": atomic.LoadInt64(&blocked)}, nil
+}
+
+// crashTester exit process of plugin
+type crashTester struct{}
+
+func (t *crashTester) Info() TesterInfo {
+	return TesterInfo{Name: "Crash"}
+}
+
+func (t *crashTester) Run(context.Context, *RunParams) (map[string]interface{}, error) {
+	os.Exit(3)
+	return nil, nil
+}
+
+type selector int
+
+func (s selector) Index() int {
+	return int(s)
+}
+
+// rawState state of raw params, results of runs are appended
+type rawState struct {
+	models.State
+	mu     sync.Mutex
+	params []map[string]interface{}
+}
+
+func (s *rawState) add(params map[string]interface{}) models.StateSelector {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.params = append(s.params, params)
+	return selector(len(s.params) - 1)
+}
+
+func (s *rawState) Raw(sel models.StateSelector) map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.params[sel.Index()]
+}
+
+func (s *rawState) NewRaw(_ models.StateSelector, values map[string]interface{}) (models.StateSelector, error) {
+	return s.add(values), nil
+}
+
+// safeBuffer buffer of logs written concurrently by readers of plugins
+type safeBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *safeBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *safeBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// loadPlugin start test binary as plugin, logs of plugin are written to buffer at debug level
+func loadPlugin(t *testing.T, state models.State, clients *clientfactory.Set) (*Plugins, map[string]models.Tester, *safeBuffer) {
+	t.Helper()
+	t.Setenv(pluginEnv, "1")
+	path, err := os.Executable()
+	if err != nil {
+		t.Fatal(err)
+	}
+	logs := &safeBuffer{}
+	logger := logrus.New()
+	logger.SetOutput(logs)
+	logger.SetLevel(logrus.DebugLevel)
+	plugins, err := Load([]string{path}, state, clients, log.NewLogger(logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = plugins.Close() })
+	testers := make(map[string]models.Tester, len(plugins.Testers()))
+	for _, tester := range plugins.Testers() {
+		testers[tester.MethodName()] = tester
+	}
+	return plugins, testers, logs
+}
+
+func TestPluginDescribe(t *testing.T) {
+	plugins, testers, logs := loadPlugin(t, &rawState{}, nil)
+	if len(testers) != 4 {
+		t.Fatalf("unexpected testers %v", testers)
+	}
+	echo := testers["Echo"]
+	if got := strings.Join(echo.RequiredFields(), ","); got != "name" {
+		t.Fatalf("unexpected required fields %s", got)
+	}
+	if got := strings.Join(echo.ReturnedFields(), ","); got != "greeting,url,auth,launch" {
+		t.Fatalf("unexpected returned fields %s", got)
+	}
+	info := echo.(models.TesterInfo)
+	if info.Description() != "greet user" || strings.Join(info.ClientKinds(), ",") != common.ClientHTTP {
+		t.Fatalf("unexpected info %s %v", info.Description(), info.ClientKinds())
+	}
+	if types := echo.(models.FieldTyped).FieldTypes(); types["name"] != "string" {
+		t.Fatalf("unexpected types %v", types)
+	}
+	// stderr of plugin is read before plugin is stopped
+	if err := plugins.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(logs.String(), "level=debug msg=\"plugin started\"") {
+		t.Fatalf("stderr of plugin isn't logged at debug level: %s", logs)
+	}
+}
+
+func TestPluginRun(t *testing.T) {
+	clients, err := clientfactory.Build([]common.Client{{Name: "api", Url: "http://localhost", Token: "token"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := &rawState{}
+	_, testers, _ := loadPlugin(t, state, clients)
+	opts := &models.Options{LaunchID: "launch"}
+
+	result, err := testers["Echo"].Run(context.Background(), "api", state.add(map[string]interface{}{"name": "bob"}), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"greeting": "hello bob", "url": "http://localhost", "auth": "Bearer token", "launch": "launch",
+	}
+	values := state.Raw(result)
+	for key, value := range expected {
+		if values[key] != value {
+			t.Fatalf("expected %s %v, got %v", key, value, values[key])
+		}
+	}
+	if _, err = testers["Echo"].Run(context.Background(), "web", state.add(nil), opts); err == nil {
+		t.Fatal("expected error of unknown client")
+	}
+}
+
+func TestPluginCancel(t *testing.T) {
+	state := &rawState{}
+	_, testers, _ := loadPlugin(t, state, nil)
+	active := func() string {
+		result, err := testers["Active"].Run(context.Background(), "", state.add(nil), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return fmt.Sprint(state.Raw(result)["blocked"])
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		_, err := testers["Block"].Run(ctx, "", state.add(nil), nil)
+		done <- err
+	}()
+	for start := time.Now(); active() != "1"; time.Sleep(time.Millisecond) {
+		if time.Since(start) > time.Second {
+			t.Fatal("run isn't started")
+		}
+	}
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected cancelled run, got %v", err)
+	}
+	// run is cancelled in process of plugin too
+	for start := time.Now(); active() != "0"; time.Sleep(time.Millisecond) {
+		if time.Since(start) > time.Second {
+			t.Fatal("run isn't cancelled in plugin")
+		}
+	}
+}
+
+func TestPluginCrash(t *testing.T) {
+	state := &rawState{}
+	_, testers, _ := loadPlugin(t, state, nil)
+
+	pending := make(chan error)
+	go func() {
+		_, err := testers["Block"].Run(context.Background(), "", state.add(nil), nil)
+		pending <- err
+	}()
+	if _, err := testers["Crash"].Run(context.Background(), "", state.add(nil), nil); err == nil {
+		t.Fatal("expected error of crashed plugin")
+	}
+	// pending calls fail, they don't wait forever
+	select {
+	case err := <-pending:
+		if err == nil || !strings.Contains(err.Error(), "exit status 3") {
+			t.Fatalf("expected error of exited plugin, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("pending call of crashed plugin isn't failed")
+	}
+	if _, err := testers["Echo"].Run(context.Background(), "", state.add(nil), nil); err == nil ||
+		!strings.Contains(err.Error(), "exit status 3") {
+		t.Fatalf("expected error of exited plugin, got %v", err)
+	}
+}
@@ -0,0 +1,93 @@
+// Package testerplugin runs testers in external processes. Plugin is executable, which speaks JSON-RPC 2.0
+// over stdin and stdout, one message per line: host calls describe once and then run concurrently,
+// cancel notification aborts run of request with id. Plugins written in Go serve their testers by Serve.
+package testerplugin
+
+import "encoding/json"
+
+const (
+	version = "2.0"
+
+	// MethodDescribe return testers of plugin, result is DescribeResult
+	MethodDescribe = "describe"
+	// MethodRun run tester, params are RunParams and result is RunResult
+	MethodRun = "run"
+	// MethodCancel notification of host, params are CancelParams
+	MethodCancel = "cancel"
+)
+
+// codes of errors of responses
+const (
+	CodeParseError     = -32700
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	// CodeRunFailed tester returned error
+	CodeRunFailed = 1
+)
+
+// Request message of JSON-RPC, notification has no id
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      uint64          `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response message of JSON-RPC
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      uint64          `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error of response
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// DescribeResult testers served by plugin
+type DescribeResult struct {
+	Testers []TesterInfo `json:"testers"`
+}
+
+// TesterInfo tester of plugin, method name must be unique among all testers of runner
+type TesterInfo struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Required    []string `json:"required"`
+	Returned    []string `json:"returned"`
+	// Clients kinds of supported clients, any client if empty
+	Clients []string `json:"clients,omitempty"`
+	// Types types of fields of state, like int or string
+	Types map[string]string `json:"types,omitempty"`
+}
+
+// RunParams run of tester with raw params of selected state
+type RunParams struct {
+	Tester   string                 `json:"tester"`
+	Client   *ClientInfo            `json:"client,omitempty"`
+	State    map[string]interface{} `json:"state"`
+	LaunchID string                 `json:"launch_id"`
+	Scenario string                 `json:"scenario"`
+}
+
+// ClientInfo client of stage, headers contain token and credentials of auth of client
+type ClientInfo struct {
+	Name    string                 `json:"name"`
+	Type    string                 `json:"type"`
+	URL     string                 `json:"url"`
+	Headers map[string]string      `json:"headers,omitempty"`
+	Extra   map[string]interface{} `json:"extra,omitempty"`
+}
+
+// RunResult values of returned fields, they are converted to types of fields of state
+type RunResult struct {
+	Fields map[string]interface{} `json:"fields"`
+}
+
+// CancelParams id of request of cancelled run
+type CancelParams struct {
+	ID uint64 `json:"id"`
+}
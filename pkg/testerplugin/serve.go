@@ -0,0 +1,157 @@
+package testerplugin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// Tester of plugin, it runs in process of plugin. Numbers of state are json.Number.
+type Tester interface {
+	Info() TesterInfo
+	Run(ctx context.Context, params *RunParams) (fields map[string]interface{}, err error)
+}
+
+// Serve testers over stdin and stdout until host closes stdin, it is main loop of plugin.
+// Plugin must not write anything else to stdout, stderr is free for logs, its lines are logged by runner.
+func Serve(testers ...Tester) error {
+	return ServeIO(os.Stdin, os.Stdout, testers...)
+}
+
+// ServeIO serve testers over reader and writer, runs are concurrent, it returns after all runs are done
+func ServeIO(in io.Reader, out io.Writer, testers ...Tester) error {
+	s := &server{
+		out:     out,
+		list:    testers,
+		testers: make(map[string]Tester, len(testers)),
+		runs:    map[uint64]context.CancelFunc{},
+	}
+	for _, tester := range testers {
+		s.testers[tester.Info().Name] = tester
+	}
+	reader := bufio.NewReader(in)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			s.handle(line)
+		}
+		if err != nil {
+			s.wg.Wait()
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+type server struct {
+	out     io.Writer
+	list    []Tester
+	testers map[string]Tester
+	wg      sync.WaitGroup
+
+	// writeMu guards out, responses are written concurrently
+	writeMu sync.Mutex
+	// mu guards cancels of running runs
+	mu   sync.Mutex
+	runs map[uint64]context.CancelFunc
+}
+
+func (s *server) handle(line []byte) {
+	req := &Request{}
+	if err := json.Unmarshal(line, req); err != nil {
+		s.fail(0, CodeParseError, err.Error())
+		return
+	}
+	switch req.Method {
+	case MethodDescribe:
+		result := DescribeResult{Testers: make([]TesterInfo, 0, len(s.list))}
+		for _, tester := range s.list {
+			result.Testers = append(result.Testers, tester.Info())
+		}
+		s.respond(req.ID, result)
+	case MethodRun:
+		params := &RunParams{}
+		decoder := json.NewDecoder(bytes.NewReader(req.Params))
+		decoder.UseNumber()
+		if err := decoder.Decode(params); err != nil {
+			s.fail(req.ID, CodeInvalidParams, err.Error())
+			return
+		}
+		tester, ok := s.testers[params.Tester]
+		if !ok {
+			s.fail(req.ID, CodeInvalidParams, fmt.Sprintf("unknown tester %s", params.Tester))
+			return
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		s.mu.Lock()
+		s.runs[req.ID] = cancel
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go s.run(ctx, req.ID, tester, params)
+	case MethodCancel:
+		params := &CancelParams{}
+		if err := json.Unmarshal(req.Params, params); err != nil {
+			return
+		}
+		s.mu.Lock()
+		cancel, ok := s.runs[params.ID]
+		s.mu.Unlock()
+		if ok {
+			cancel()
+		}
+	default:
+		if req.ID != 0 {
+			s.fail(req.ID, CodeMethodNotFound, fmt.Sprintf("unknown method %s", req.Method))
+		}
+	}
+}
+
+func (s *server) run(ctx context.Context, id uint64, tester Tester, params *RunParams) {
+	defer s.wg.Done()
+	defer func() {
+		s.mu.Lock()
+		cancel := s.runs[id]
+		delete(s.runs, id)
+		s.mu.Unlock()
+		cancel()
+	}()
+	fields, err := tester.Run(ctx, params)
+	if err != nil {
+		s.fail(id, CodeRunFailed, err.Error())
+		return
+	}
+	if fields == nil {
+		fields = map[string]interface{}{}
+	}
+	s.respond(id, RunResult{Fields: fields})
+}
+
+func (s *server) respond(id uint64, result interface{}) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		s.fail(id, CodeRunFailed, err.Error())
+		return
+	}
+	s.write(Response{JSONRPC: version, ID: id, Result: data})
+}
+
+func (s *server) fail(id uint64, code int, message string) {
+	s.write(Response{JSONRPC: version, ID: id, Error: &Error{Code: code, Message: message}})
+}
+
+func (s *server) write(resp Response) {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	_, _ = s.out.Write(append(data, '\n'))
+}
@@ -0,0 +1,50 @@
+package testerspool
+
+import (
+	"github.com/lueurxax/e2e/pkg/models"
+)
+
+// Describe metadata of testers of pool sorted by method name, types of fields are taken from tester
+// and then from state, if they implement models.FieldTyped
+func Describe(pool TestersPool, state models.State) []models.TesterMeta {
+	var stateTypes map[string]string
+	if typed, ok := state.(models.FieldTyped); ok {
+		stateTypes = typed.FieldTypes()
+	}
+	testers := pool.All()
+	metas := make([]models.TesterMeta, len(testers))
+	for i, tester := range testers {
+		metas[i] = Meta(tester, stateTypes)
+	}
+	return metas
+}
+
+// Meta metadata of tester, types of fields unknown to tester are taken from types of state
+func Meta(tester models.Tester, stateTypes map[string]string) models.TesterMeta {
+	var testerTypes map[string]string
+	if typed, ok := tester.(models.FieldTyped); ok {
+		testerTypes = typed.FieldTypes()
+	}
+	fields := func(names []string) []models.FieldMeta {
+		result := make([]models.FieldMeta, len(names))
+		for i, name := range names {
+			fieldType, ok := testerTypes[name]
+			if !ok {
+				fieldType = stateTypes[name]
+			}
+			result[i] = models.FieldMeta{Name: name, Type: fieldType}
+		}
+		return result
+	}
+	meta := models.TesterMeta{
+		Name:     tester.MethodName(),
+		Required: fields(tester.RequiredFields()),
+		Returned: fields(tester.ReturnedFields()),
+		Clients:  make([]string, 0),
+	}
+	if info, ok := tester.(models.TesterInfo); ok {
+		meta.Description = info.Description()
+		meta.Clients = append(meta.Clients, info.ClientKinds()...)
+	}
+	return meta
+}
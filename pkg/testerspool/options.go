@@ -0,0 +1,93 @@
+package testerspool
+
+import (
+	"fmt"
+
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/clientfactory"
+	"github.com/lueurxax/e2e/pkg/grpctester"
+	"github.com/lueurxax/e2e/pkg/httptester"
+	"github.com/lueurxax/e2e/pkg/log"
+	"github.com/lueurxax/e2e/pkg/models"
+	"github.com/lueurxax/e2e/pkg/scripttester"
+	"github.com/lueurxax/e2e/pkg/testerplugin"
+)
+
+// Option add testers to pool, names of added testers must not duplicate registered testers
+type Option func(p *pool) error
+
+// WithHTTP add declarative http testers registered under configured names
+func WithHTTP(confs []common.HTTPTester, state models.State, clients *clientfactory.Set) Option {
+	return func(p *pool) error {
+		testers, err := httptester.NewTesters(confs, state, clients)
+		if err != nil {
+			return err
+		}
+		return p.add(testers...)
+	}
+}
+
+// WithGRPC add declarative grpc testers registered under configured names
+func WithGRPC(confs []common.GRPCTester, state models.State, clients *clientfactory.Set) Option {
+	return func(p *pool) error {
+		testers, err := grpctester.NewTesters(confs, state, clients)
+		if err != nil {
+			return err
+		}
+		return p.add(testers...)
+	}
+}
+
+// WithScripts add script testers registered under configured names, output of scripts is logged by logger
+func WithScripts(
+	confs []common.ScriptTester, state models.State, clients *clientfactory.Set, logger log.Logger,
+) Option {
+	return func(p *pool) error {
+		testers, err := scripttester.NewTesters(confs, state, clients, logger)
+		if err != nil {
+			return err
+		}
+		return p.add(testers...)
+	}
+}
+
+// WithPlugins add testers of plugins started from executables of directory, pool implements io.Closer,
+// which stops plugins. Empty directory means no plugins, stderr of plugins is logged by logger.
+func WithPlugins(dir string, state models.State, clients *clientfactory.Set, logger log.Logger) Option {
+	return func(p *pool) error {
+		if dir == "" {
+			return nil
+		}
+		plugins, err := testerplugin.LoadDir(dir, state, clients, logger)
+		if err != nil {
+			return err
+		}
+		// plugins are stopped with pool, also if their testers duplicate registered testers
+		p.plugins = append(p.plugins, plugins)
+		return p.add(plugins.Testers()...)
+	}
+}
+
+// add testers to pool, names of added testers are unique
+func (t *pool) add(testers ...models.Tester) error {
+	for _, tester := range testers {
+		if _, ok := t.storage[tester.MethodName()]; ok {
+			return common.ErrInvalidConfig(fmt.Sprintf("tester %s duplicates registered tester", tester.MethodName()))
+		}
+		t.storage[tester.MethodName()] = tester
+	}
+	return nil
+}
+
+// NewTestersPoolWithOptions constructor for testers pool of registered testers and testers added by options,
+// started plugins are stopped on error
+func NewTestersPoolWithOptions(testers []models.Tester, opts ...Option) (TestersPool, error) {
+	p := NewTestersPool(testers).(*pool)
+	for _, opt := range opts {
+		if err := opt(p); err != nil {
+			_ = p.Close()
+			return nil, err
+		}
+	}
+	return p, nil
+}
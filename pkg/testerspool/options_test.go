@@ -0,0 +1,64 @@
+package testerspool
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/lueurxax/e2e/common"
+	"github.com/lueurxax/e2e/pkg/log"
+	"github.com/lueurxax/e2e/pkg/models"
+)
+
+type tester struct {
+	models.Tester
+	name string
+}
+
+func (t *tester) MethodName() string {
+	return t.name
+}
+
+func (t *tester) Run(context.Context, string, models.StateSelector, *models.Options) (models.StateSelector, error) {
+	return nil, nil
+}
+
+// rawState state of testers of declarative configs
+type rawState struct {
+	models.State
+	models.RawState
+}
+
+func TestNewTestersPoolWithOptions(t *testing.T) {
+	registered := []models.Tester{&tester{name: "Login"}}
+	state := &rawState{}
+	pool, err := NewTestersPoolWithOptions(registered,
+		WithHTTP([]common.HTTPTester{{Name: "GetUser", URL: "https://example.com/user"}}, state, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	testers := pool.All()
+	if len(testers) != 2 || testers[0].MethodName() != "GetUser" || testers[1].MethodName() != "Login" {
+		t.Fatalf("unexpected testers %v", testers)
+	}
+
+	_, err = NewTestersPoolWithOptions(registered,
+		WithHTTP([]common.HTTPTester{{Name: "Login", URL: "https://example.com/login"}}, state, nil))
+	if err == nil || !strings.Contains(err.Error(), "tester Login duplicates registered tester") {
+		t.Fatalf("expected error of duplicated tester, got %v", err)
+	}
+
+	// empty directory means no plugins
+	logger := log.NewLogger(logrus.New())
+	if pool, err = NewTestersPoolWithOptions(registered, WithPlugins("", state, nil, logger)); err != nil {
+		t.Fatal(err)
+	}
+	if len(pool.All()) != 1 {
+		t.Fatalf("unexpected testers %v", pool.All())
+	}
+	if _, err = NewTestersPoolWithOptions(registered, WithPlugins(t.TempDir()+"/missing", state, nil, logger)); err == nil {
+		t.Fatal("expected error of missing directory of plugins")
+	}
+}
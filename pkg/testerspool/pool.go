@@ -1,6 +1,9 @@
 package testerspool
 
 import (
+	"io"
+	"sort"
+
 	"github.com/lueurxax/e2e/common"
 	"github.com/lueurxax/e2e/pkg/models"
 )
@@ -8,10 +11,14 @@ import (
 // TestersPool readonly pool of tester interfaces
 type TestersPool interface {
 	Get(key string) (tester models.Tester, err error)
+	// All registered testers sorted by method name
+	All() (testers []models.Tester)
 }
 
 type pool struct {
 	storage map[string]models.Tester
+	// plugins processes of testers of plugins
+	plugins []io.Closer
 }
 
 // Get tester from pool
@@ -24,6 +31,28 @@ func (t *pool) Get(key string) (tester models.Tester, err error) {
 	return
 }
 
+// All registered testers sorted by method name
+func (t *pool) All() []models.Tester {
+	testers := make([]models.Tester, 0, len(t.storage))
+	for _, tester := range t.storage {
+		testers = append(testers, tester)
+	}
+	sort.Slice(testers, func(i, j int) bool {
+		return testers[i].MethodName() < testers[j].MethodName()
+	})
+	return testers
+}
+
+// Close stop processes of plugins
+func (t *pool) Close() (err error) {
+	for _, plugins := range t.plugins {
+		if closeErr := plugins.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return
+}
+
 // NewTestersPool constructor for testers pool
 func NewTestersPool(testers []models.Tester) TestersPool {
 	p := map[string]models.Tester{}